@@ -1,25 +1,40 @@
 package eztv
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
+	"io"
+	"iter"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 const (
 	EZTVBaseURL           = "https://eztv.re/api"
 	StreamRecheckInterval = 5 * time.Minute
 	MaxEZTVAPILimit       = 100
+	LowMemoryResyncLimit  = 10
 )
 
-var ErrMissingImdbID = errors.New("missing imdbID")
+var (
+	ErrMissingImdbID   = errors.New("missing imdbID")
+	ErrInvalidImdbID   = errors.New("invalid imdbID")
+	ErrSearchTruncated = errors.New("search truncated")
+	ErrRequestTimeout  = errors.New("request timed out")
+	ErrInvalidPage     = errors.New("invalid page")
+	ErrUnsupported     = errors.New("unsupported option")
+)
 
 // URLOptions are the options that can be passed into EZTV API
 // for custom data retrieval.
@@ -27,10 +42,19 @@ type URLOptions struct {
 	// Specifies the page number to retrieve. Default is 1.
 	Page int
 	// Specifies the number of torrents to retrieve. Default is 30.
-	// API has a hard limit of 100 torrents per page and minimum limit of 1.
+	// API has a hard limit of 100 torrents per page and minimum limit of 1,
+	// so values above MaxEZTVAPILimit are clamped to it and negative values
+	// fall back to the default.
 	Limit int
 	// ImdbID tag will retrieve torrents only for that exact show.
 	ImdbID string
+	// MinSeeds drops the torrents with fewer seeds from the returned page. The API cannot filter
+	// by seeds, so this is done client-side: the page is fetched in full and may end up with fewer
+	// than Limit torrents, while its TorrentsCount stays as reported by the API.
+	MinSeeds int
+	// Sort sorts the torrents of the returned page client-side. Default is SortNone,
+	// which keeps the order of the API.
+	Sort SortBy
 }
 
 // StreamOptions allow to customize the behaviour of the TorrentStream.
@@ -41,12 +65,123 @@ type StreamOptions struct {
 	LastTorrentID int
 	// Specifies how often to re-check for new torrents.
 	RecheckInterval time.Duration
+	// Jitter randomly moves every recheck up to Jitter earlier or later than RecheckInterval,
+	// so that streams started together do not all poll the API at the same time.
+	// Zero rechecks exactly every RecheckInterval.
+	Jitter time.Duration
+	// LowMemory makes the full re-sync fetch pages of LowMemoryResyncLimit torrents
+	// instead of MaxEZTVAPILimit. This bounds the number of torrents held in memory at once,
+	// at the cost of making ten times more requests.
+	LowMemory bool
+	// Debounce holds back newly found torrents until no new ones have appeared for the given
+	// duration, and then emits them all together. Zero emits every torrent as soon as it is found.
+	Debounce time.Duration
+	// MaxDuration closes the stream after it has been running for the given duration.
+	// Zero keeps the stream running until the context is cancelled.
+	MaxDuration time.Duration
+	// WatchdogWindow is how long SupervisedStream waits for a successful poll
+	// before restarting the stream. Default is three times the RecheckInterval.
+	WatchdogWindow time.Duration
+	// KnownTorrentCount is the number of torrents the show is known to have. When set, the full re-sync
+	// uses it to compute the pages to fetch instead of requesting it from the API first.
+	// An outdated count shifts the pages that are fetched, so it should be as accurate as possible.
+	KnownTorrentCount int
+	// StrictChronological makes the full re-sync emit torrents strictly in ascending release date
+	// (and then ID) order across the whole history. To do so, every torrent of the show is buffered
+	// in memory and sorted before the first one is emitted. If fetching any page fails,
+	// none of the buffered torrents are emitted and the re-sync is tried again after RecheckInterval.
+	StrictChronological bool
+	// PollTimeout bounds every request the stream makes. A request that times out is pushed
+	// as an error onto the stream, which then carries on. Zero leaves requests bounded by the context only.
+	PollTimeout time.Duration
+	// FullSnapshotMode makes every recheck emit the whole latest page of MaxEZTVAPILimit torrents,
+	// including the ones emitted before, with StreamTorrent.Snapshot set. This keeps e.g. seed counts
+	// of a local copy of the latest torrents fresh. Debounce does not apply to snapshots, and since they
+	// repeat by design, they are neither saved as seen to a SeenStore nor skipped for having been seen.
+	FullSnapshotMode bool
+	// RecentBufferSize is the number of most recently pushed torrents a Stream opened
+	// with OpenStream keeps available through Stream.Recent.
+	RecentBufferSize int
+	// MaxAge makes the full re-sync skip torrents released longer than MaxAge ago, going by DateReleasedUnix.
+	// Torrents with an unknown release date are skipped too, unless IncludeUnknownDates is set.
+	// Zero re-syncs torrents of any age.
+	MaxAge time.Duration
+	// IncludeUnknownDates makes the full re-sync keep torrents with an unknown release date when MaxAge is set.
+	IncludeUnknownDates bool
+	// CurrentBest turns the stream into an upgrade notifier. It seeds the best release known of every episode,
+	// and only torrents that are an upgrade of one of them by UpgradePrefs (see Torrent.IsUpgrade) are emitted,
+	// becoming the best release of those episodes. Every other torrent, including those of episodes missing
+	// from CurrentBest, is skipped but still moves the stream past it. The stream works on a copy of the map.
+	// Snapshots are never skipped. Nil emits every torrent.
+	CurrentBest map[SeasonEpisode]Torrent
+	// UpgradePrefs are the quality preferences upgrades of CurrentBest are judged by.
+	UpgradePrefs QualityPrefs
+	// ResyncDelay is how long the full re-sync waits between its requests, to spread them out
+	// instead of fetching every page back-to-back. Zero does not wait.
+	ResyncDelay time.Duration
+	// ResyncConcurrency is how many pages the full re-sync fetches at once. Pages fetched ahead are held
+	// until the ones before them are emitted, so torrents are still emitted in ascending ID order.
+	// Default is 1, which fetches one page at a time.
+	ResyncConcurrency int
+	// MaxEmitRate caps how many torrents per second the stream pushes, e.g. to not flood a slow consumer
+	// during the full re-sync. Torrents are held back until the rate allows them. Zero does not cap the rate.
+	MaxEmitRate rate.Limit
+	// StateStore persists the stream's last torrent ID whenever OnProgress would be called, so it is
+	// always safe to resume from. When set and LastTorrentID is 0, the stream resumes from the ID
+	// saved in it instead of doing a full re-sync.
+	// If it is also a SeenStore, the IDs of the last SeenWindow emitted torrents are saved too,
+	// and the stream never emits any of them again, even across restarts.
+	StateStore StateStore
+	// SeenWindow is the number of most recently emitted torrent IDs saved to a SeenStore.
+	// Default is DefaultSeenWindow.
+	SeenWindow int
+	// OnProgress is called with the stream's last torrent ID every time it advances, including
+	// during the full re-sync, e.g. to checkpoint it and pass it as LastTorrentID after a restart.
+	// It is called from the stream's goroutine only after every torrent up to that ID was received
+	// from the stream or deliberately skipped, such as for MaxAge, so resuming from it never misses
+	// a torrent. With StrictChronological, it is called once the whole re-sync was emitted.
+	OnProgress func(lastTorrentID int)
+
+	// onPoll is called after every successful request made by the stream.
+	onPoll func()
+	// now and sleep replace time.Now and sleep for the MaxEmitRate and ResyncDelay waits, if set.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
 // Client is the EZTV API client. It can make requests to the EZTV API to retrieve data.
 type Client struct {
-	client  *http.Client
-	baseURL string
+	client         *http.Client
+	mirrors        []string
+	lastGoodMirror atomic.Int64
+	name           string
+	userAgent      string
+
+	treat404AsEmpty bool
+	verifyOrdering  bool
+	decodeRetries   int
+	canonicalHashes bool
+	backfillImdbID  bool
+	transforms      []func(*Torrent)
+	signer          func(*http.Request) error
+	requestHook     func(*http.Request)
+	responseHook    func(*http.Response, error, time.Duration)
+	retry           retryPolicy
+	limiter         *rate.Limiter
+	requestTimeout  time.Duration
+	resolver        IMDbResolver
+	trackers        TrackerProvider
+	trackerTimeout  time.Duration
+	maxSearchPages  int
+	metrics         Metrics
+	logger          *slog.Logger
+	emitted         *idSet
+	shared          *sharedStreams
+	recordDir       string
+	replayDir       string
+	strictInit      bool
+	// initErr is the invalid configuration found by WithStrictInit, returned by every request.
+	initErr error
 }
 
 // New returns a new Client with a default http.Client.
@@ -54,25 +189,433 @@ type Client struct {
 // Custom options can be passed to set different behaviour.
 func New(ops ...Option) *Client {
 	client := &Client{
-		client:  http.DefaultClient,
-		baseURL: EZTVBaseURL,
+		client:         http.DefaultClient,
+		mirrors:        []string{EZTVBaseURL},
+		trackers:       DefaultTrackers,
+		trackerTimeout: DefaultTrackerTimeout,
+		metrics:        noopMetrics{},
+		logger:         slog.New(discardHandler{}),
+		emitted:        newIDSet(emittedIDsCapacity),
 	}
 
 	for _, op := range ops {
 		op(client)
 	}
+	if client.logger == nil {
+		client.logger = slog.New(discardHandler{})
+	}
+	if client.name != "" {
+		client.logger = client.logger.With("client", client.name)
+	}
+
+	// A nil http client is left for WithStrictInit to report.
+	if client.recordDir != "" && client.client != nil {
+		client.client = withTransport(client.client, func(next http.RoundTripper) http.RoundTripper {
+			return &recorder{next: next, dir: client.recordDir}
+		})
+	}
+	if client.replayDir != "" && client.client != nil {
+		client.client = withTransport(client.client, func(http.RoundTripper) http.RoundTripper {
+			return &replayer{dir: client.replayDir}
+		})
+	}
+
+	if client.strictInit {
+		if client.initErr = client.validate(); client.initErr != nil {
+			client.logger.Error("rejected client configuration", "error", client.initErr)
+		}
+	}
 
 	return client
 }
 
+// NewStrict works like New with WithStrictInit, but returns the error describing every
+// invalid option, e.g. a base URL that does not parse, instead of a client.
+func NewStrict(ops ...Option) (*Client, error) {
+	client := New(append(slices.Clip(ops), WithStrictInit())...)
+	if client.initErr != nil {
+		return nil, client.initErr
+	}
+	return client, nil
+}
+
+// validate checks the client configuration.
+func (c *Client) validate() error {
+	var errs []error
+	if c.client == nil {
+		errs = append(errs, errors.New("http client is nil"))
+	}
+	if len(c.mirrors) == 0 {
+		errs = append(errs, errors.New("no base url"))
+	}
+	for i, mirror := range c.mirrors {
+		if u, err := url.Parse(mirror); err != nil {
+			errs = append(errs, fmt.Errorf("base url: %w", err))
+		} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("base url %q is not an absolute http(s) url", mirror))
+		}
+		if slices.ContainsFunc(c.mirrors[:i], func(other string) bool { return SameMirror(mirror, other) }) {
+			errs = append(errs, fmt.Errorf("duplicate mirror %q", mirror))
+		}
+	}
+	if c.decodeRetries < 0 {
+		errs = append(errs, fmt.Errorf("negative decode retries %d", c.decodeRetries))
+	}
+	if c.retry.maxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("negative max retry attempts %d", c.retry.maxAttempts))
+	}
+	if c.retry.baseDelay < 0 {
+		errs = append(errs, fmt.Errorf("negative retry base delay %s", c.retry.baseDelay))
+	}
+	if c.requestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("negative request timeout %s", c.requestTimeout))
+	}
+	if c.trackers == nil {
+		errs = append(errs, errors.New("tracker provider is nil"))
+	}
+	if c.trackerTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("tracker timeout %s is not positive", c.trackerTimeout))
+	}
+	if c.maxSearchPages < 0 {
+		errs = append(errs, fmt.Errorf("negative max search pages %d", c.maxSearchPages))
+	}
+	if c.metrics == nil {
+		errs = append(errs, errors.New("metrics is nil"))
+	}
+	if c.recordDir != "" && c.replayDir != "" {
+		errs = append(errs, errors.New("recorder and replayer cannot be used together"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid client configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Name returns the name the client was configured with using WithClientName.
+// Unnamed clients return an empty string.
+func (c *Client) Name() string {
+	return c.name
+}
+
 // GetTorrents returns a Page of torrents from the EZTV API.
 //
 // URLOptions allow to customize the data that is retrieved.
-// API has a hard limit of max 100 torrents per page. More than that will
-// default to 30.
+// API has a hard limit of max 100 torrents per page, so a higher Limit
+// is clamped to MaxEZTVAPILimit before the request is sent.
+//
+// An ImdbID that is not all digits after its "tt" prefix is rejected with ErrInvalidImdbID
+// before any request is sent.
+//
+// If the API responds with a 4xx or 5xx status code, an *APIError is returned.
 func (c *Client) GetTorrents(ctx context.Context, urlOptions URLOptions) (*Page, error) {
-	url := fmt.Sprintf("%s/get-torrents", EZTVBaseURL)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	page, _, err := c.GetTorrentsWithQuery(ctx, urlOptions)
+	return page, err
+}
+
+// GetTorrentsWithQuery works like GetTorrents, but also returns the query parameters
+// that were sent to the API, after URLOptions were normalized.
+func (c *Client) GetTorrentsWithQuery(ctx context.Context, urlOptions URLOptions) (*Page, url.Values, error) {
+	page, meta, err := c.GetTorrentsWithResponse(ctx, urlOptions)
+	if meta == nil {
+		return page, nil, err
+	}
+	return page, meta.Query, err
+}
+
+// GetTorrentsWithResponse works like GetTorrents, but also returns the ResponseMeta
+// of the API response. The ResponseMeta is returned whenever the request was built,
+// even if it failed afterwards.
+func (c *Client) GetTorrentsWithResponse(ctx context.Context, urlOptions URLOptions) (*Page, *ResponseMeta, error) {
+	page, meta, err := getJSON[Page](ctx, c, urlOptions)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	if err := c.processPage(ctx, page, meta, urlOptions); err != nil {
+		return nil, meta, err
+	}
+	return page, meta, nil
+}
+
+// processPage checks and post-processes a page fetched for the URLOptions as configured on the client.
+func (c *Client) processPage(ctx context.Context, page *Page, meta *ResponseMeta, urlOptions URLOptions) error {
+	markBeyondLast(page, urlOptions)
+	if err := checkPageConsistency(page, urlOptions); err != nil {
+		return err
+	}
+
+	if c.canonicalHashes {
+		for i := range page.Torrents {
+			canonicalizeHashes(&page.Torrents[i])
+		}
+	}
+
+	if imdbID := meta.Query.Get("imdb_id"); c.backfillImdbID && imdbID != "" {
+		for i := range page.Torrents {
+			if page.Torrents[i].ImdbID == "" {
+				page.Torrents[i].ImdbID = imdbID
+			}
+		}
+	}
+
+	for _, transform := range c.transforms {
+		for i := range page.Torrents {
+			transform(&page.Torrents[i])
+		}
+	}
+
+	if urlOptions.MinSeeds > 0 {
+		page.Torrents = slices.DeleteFunc(page.Torrents, func(t Torrent) bool { return t.Seeds < urlOptions.MinSeeds })
+	}
+
+	if c.verifyOrdering && sortNewestFirst(page.Torrents) {
+		c.logger.WarnContext(ctx, "reordered torrents returned out of order", "query", logQuery(meta.Query))
+	}
+	sortTorrents(page.Torrents, urlOptions.Sort)
+
+	c.logger.DebugContext(ctx, "fetched torrents", "query", logQuery(meta.Query), "torrents", len(page.Torrents), "torrents_count", page.TorrentsCount)
+	return nil
+}
+
+// GetTorrentIDsAndMagnets works like GetTorrents, but only decodes the ID and magnet link
+// of each torrent, which saves allocations on large pages.
+//
+// The page is checked for consistency and for being beyond the last one like GetTorrents does,
+// and WithCanonicalHashes and WithVerifyOrdering apply to the magnets. The options that need
+// the other fields of the torrents do not: WithResultTransforms and WithBackfillImdbID are ignored,
+// and URLOptions.MinSeeds and Sort are rejected with ErrUnsupported.
+func (c *Client) GetTorrentIDsAndMagnets(ctx context.Context, urlOptions URLOptions) ([]TorrentMagnet, error) {
+	if urlOptions.MinSeeds > 0 || urlOptions.Sort != SortNone {
+		return nil, fmt.Errorf("%w: MinSeeds and Sort need the full torrents, use GetTorrents", ErrUnsupported)
+	}
+
+	page, meta, err := getJSON[struct {
+		TorrentsCount int             `json:"torrents_count"`
+		Limit         int             `json:"limit"`
+		Page          int             `json:"page"`
+		Torrents      []TorrentMagnet `json:"torrents"`
+	}](ctx, c, urlOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &Page{TorrentsCount: page.TorrentsCount, Limit: page.Limit, Page: page.Page}
+	if beyondLast(header, urlOptions) {
+		return []TorrentMagnet{}, nil
+	}
+	if len(page.Torrents) == 0 {
+		if err := checkPageConsistency(header, urlOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.canonicalHashes {
+		for i := range page.Torrents {
+			page.Torrents[i].Magnet = canonicalMagnet(page.Torrents[i].Magnet)
+		}
+	}
+
+	newestFirst := func(a, b TorrentMagnet) int { return cmp.Compare(b.ID, a.ID) }
+	if c.verifyOrdering && !slices.IsSortedFunc(page.Torrents, newestFirst) {
+		slices.SortStableFunc(page.Torrents, newestFirst)
+		c.logger.WarnContext(ctx, "reordered torrents returned out of order", "query", logQuery(meta.Query))
+	}
+
+	return page.Torrents, nil
+}
+
+// GetTorrentsComprehensive returns the latest torrents of the show, merging the show's first page
+// with the show's torrents found on the latest global feed, which may not be indexed under the show yet.
+// Torrents are deduplicated by ID and returned newest first.
+func (c *Client) GetTorrentsComprehensive(ctx context.Context, imdbID string) ([]Torrent, error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return nil, ErrMissingImdbID
+	}
+
+	show, err := c.GetTorrents(ctx, URLOptions{
+		ImdbID: imdbID,
+		Page:   1,
+		Limit:  MaxEZTVAPILimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	global, err := c.GetTorrents(ctx, URLOptions{
+		Page:  1,
+		Limit: MaxEZTVAPILimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]struct{}, len(show.Torrents))
+	torrents := make([]Torrent, 0, len(show.Torrents))
+	for _, torrent := range show.Torrents {
+		seen[torrent.ID] = struct{}{}
+		torrents = append(torrents, torrent)
+	}
+	for _, torrent := range global.Torrents {
+		if strings.TrimPrefix(torrent.ImdbID, "tt") != imdbID {
+			continue
+		}
+		if _, ok := seen[torrent.ID]; ok {
+			continue
+		}
+		seen[torrent.ID] = struct{}{}
+		torrents = append(torrents, torrent)
+	}
+	sortNewestFirst(torrents)
+
+	return torrents, nil
+}
+
+// GetAllTorrents returns every torrent of the show, newest first. It fetches the first page
+// of MaxEZTVAPILimit torrents to learn how many pages there are, counted with the limit
+// the API answered with, and then the rest of them.
+// Torrents that shift across pages while they are fetched are only returned once.
+//
+// If fetching any page fails, the error is returned and no torrents are.
+func (c *Client) GetAllTorrents(ctx context.Context, imdbID string) ([]Torrent, error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return nil, ErrMissingImdbID
+	}
+
+	var (
+		torrents []Torrent
+		seen     = make(map[int]struct{})
+		pages    = 1
+	)
+	for i := 1; i <= pages; i++ {
+		page, err := c.GetTorrents(ctx, URLOptions{
+			ImdbID: imdbID,
+			Page:   i,
+			Limit:  MaxEZTVAPILimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get page %d: %w", i, err)
+		}
+		if i == 1 {
+			if page.TorrentsCount == 0 {
+				return []Torrent{}, nil
+			}
+			pages = page.lastPage(MaxEZTVAPILimit)
+			torrents = make([]Torrent, 0, page.TorrentsCount)
+		}
+
+		for _, torrent := range page.Torrents {
+			if _, ok := seen[torrent.ID]; ok {
+				continue
+			}
+			seen[torrent.ID] = struct{}{}
+			torrents = append(torrents, torrent)
+		}
+	}
+	sortNewestFirst(torrents)
+
+	return torrents, nil
+}
+
+// AllTorrents returns an iterator over every torrent of the show, newest first. Pages of
+// MaxEZTVAPILimit torrents are fetched one at a time as the iteration reaches them, so breaking
+// out of the loop early saves fetching the rest. Torrents that shift across pages while they
+// are fetched are only yielded once.
+//
+// A page that fails to be fetched is yielded as an error. If the consumer carries on, the page
+// is fetched again, unless ctx is done, which ends the iteration.
+func (c *Client) AllTorrents(ctx context.Context, imdbID string) iter.Seq2[Torrent, error] {
+	return c.AllTorrentsFrom(ctx, imdbID, 1)
+}
+
+// AllTorrentsFrom works like AllTorrents, but starts the iteration at startPage of MaxEZTVAPILimit
+// torrents, skipping the newer ones, e.g. to resume an export near where it stopped.
+// A startPage below 1 is yielded as an error wrapping ErrInvalidPage.
+func (c *Client) AllTorrentsFrom(ctx context.Context, imdbID string, startPage int) iter.Seq2[Torrent, error] {
+	return func(yield func(Torrent, error) bool) {
+		imdbID := strings.TrimPrefix(imdbID, "tt")
+		if imdbID == "" {
+			yield(Torrent{}, ErrMissingImdbID)
+			return
+		}
+		if startPage < 1 {
+			yield(Torrent{}, fmt.Errorf("%w: start page %d is below 1", ErrInvalidPage, startPage))
+			return
+		}
+
+		var previous map[int]struct{}
+		for i := startPage; ; {
+			page, err := c.GetTorrents(ctx, URLOptions{
+				ImdbID: imdbID,
+				Page:   i,
+				Limit:  MaxEZTVAPILimit,
+			})
+			if err != nil {
+				if !yield(Torrent{}, fmt.Errorf("get page %d: %w", i, err)) || ctx.Err() != nil {
+					return
+				}
+				continue
+			}
+
+			current := make(map[int]struct{}, len(page.Torrents))
+			for _, torrent := range page.Torrents {
+				current[torrent.ID] = struct{}{}
+				if _, ok := previous[torrent.ID]; ok {
+					continue
+				}
+				if !yield(torrent, nil) {
+					return
+				}
+			}
+			previous = current
+
+			if len(page.Torrents) == 0 || i >= page.lastPage(MaxEZTVAPILimit) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// getJSON sends the get-torrents request for the given URLOptions and decodes the JSON response body
+// into a new T. The mirrors are tried in order, starting from the last one that responded successfully,
+// until one of them does. If all of them fail, the errors of every mirror are returned joined.
+func getJSON[T any](ctx context.Context, c *Client, urlOptions URLOptions) (*T, *ResponseMeta, error) {
+	if len(c.mirrors) == 0 {
+		return nil, nil, errors.New("no base url")
+	}
+
+	var (
+		errs []error
+		meta *ResponseMeta
+	)
+	start := int(c.lastGoodMirror.Load())
+	for i := range len(c.mirrors) {
+		n := (start + i) % len(c.mirrors)
+		req, err := c.newTorrentsRequest(ctx, c.mirrors[n], urlOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var v *T
+		v, meta, err = doJSON[T](c, req)
+		if err == nil {
+			c.lastGoodMirror.Store(int64(n))
+			return v, meta, nil
+		}
+		if len(c.mirrors) == 1 || ctx.Err() != nil {
+			return nil, meta, err
+		}
+		errs = append(errs, fmt.Errorf("mirror %s: %w", c.mirrors[n], err))
+	}
+
+	return nil, meta, fmt.Errorf("all mirrors failed: %w", errors.Join(errs...))
+}
+
+// newTorrentsRequest builds the get-torrents request to the mirror for the given URLOptions.
+func (c *Client) newTorrentsRequest(ctx context.Context, mirror string, urlOptions URLOptions) (*http.Request, error) {
+	req, err := c.newRequest(ctx, fmt.Sprintf("%s/get-torrents", strings.TrimRight(mirror, "/")))
 	if err != nil {
 		return nil, err
 	}
@@ -81,49 +624,272 @@ func (c *Client) GetTorrents(ctx context.Context, urlOptions URLOptions) (*Page,
 	if urlOptions.Page != 0 {
 		q.Add("page", strconv.Itoa(urlOptions.Page))
 	}
-	if urlOptions.Limit != 0 {
-		q.Add("limit", strconv.Itoa(urlOptions.Limit))
+	if urlOptions.Limit > 0 {
+		q.Add("limit", strconv.Itoa(min(urlOptions.Limit, MaxEZTVAPILimit)))
 	}
 	if urlOptions.ImdbID != "" {
 		// If ImdbID starts is something like "tt1234567", we need to trim it to "1234567"
 		// otherwise the API will not recognize it.
 		urlOptions.ImdbID = strings.TrimPrefix(urlOptions.ImdbID, "tt")
+		if err := checkImdbID(urlOptions.ImdbID); err != nil {
+			return nil, err
+		}
 		q.Add("imdb_id", urlOptions.ImdbID)
 	}
 	req.URL.RawQuery = q.Encode()
 
+	return req, nil
+}
+
+// checkImdbID returns ErrInvalidImdbID unless the ImdbID, with its "tt" prefix already trimmed,
+// is all digits, as the API does not recognize anything else and returns an empty page for it.
+// An ImdbID that was only the prefix returns ErrMissingImdbID.
+func checkImdbID(imdbID string) error {
+	if imdbID == "" {
+		return ErrMissingImdbID
+	}
+	if strings.ContainsFunc(imdbID, func(r rune) bool { return r < '0' || r > '9' }) {
+		return fmt.Errorf("%w: %q", ErrInvalidImdbID, imdbID)
+	}
+	return nil
+}
+
+// newRequest builds a GET request for the URL with the headers configured on the client.
+func (c *Client) newRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	if c.initErr != nil {
+		return nil, c.initErr
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	return req, nil
+}
+
+// doJSON sends the request and decodes the JSON response body into a new T.
+func doJSON[T any](c *Client, req *http.Request) (*T, *ResponseMeta, error) {
+	return do(c, req, requestOptions{emptyOn404: c.treat404AsEmpty}, func(body io.Reader) (*T, error) {
+		v := new(T)
+		if err := json.NewDecoder(body).Decode(v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+// requestOptions tell do how to send a request.
+type requestOptions struct {
+	// emptyOn404 makes a 404 response result in a new T instead of an error.
+	emptyOn404 bool
+	// download marks a request that is not made to the EZTV API, such as of a .torrent file.
+	// It is retried like any request, but neither rate limited, signed, passed to the hooks
+	// nor observed by the Metrics, which are all meant for the API.
+	download bool
+}
+
+// do sends the request and reads the response body with read.
+//
+// If the body turns out to be truncated, the request is re-issued up to
+// the number of times set with WithDecodeRetry. Other transient failures are
+// retried as configured with WithRetry.
+func do[T any](c *Client, req *http.Request, opts requestOptions, read func(body io.Reader) (*T, error)) (*T, *ResponseMeta, error) {
+	meta := &ResponseMeta{Query: req.URL.Query()}
+	attempt, decodeRetries := 1, 0
+	for {
+		if c.limiter != nil && !opts.download {
+			if err := c.limiter.Wait(req.Context()); err != nil {
+				return nil, meta, err
+			}
+		}
+
+		v, err := doOnce(c, req, meta, opts, read)
+		switch {
+		case err == nil:
+			return v, meta, nil
+		case errors.Is(err, io.ErrUnexpectedEOF) && decodeRetries < c.decodeRetries:
+			decodeRetries++
+			continue
+		case !shouldRetry(req.Context(), err):
+			return nil, meta, err
+		case attempt >= c.retry.maxAttempts:
+			if attempt > 1 {
+				err = &RetryExhaustedError{Attempts: attempt, Err: err}
+			}
+			return nil, meta, err
+		}
+
+		if err := sleep(req.Context(), c.retry.delay(attempt, err, meta.Header)); err != nil {
+			return nil, meta, err
+		}
+		attempt++
+	}
+}
+
+func doOnce[T any](c *Client, req *http.Request, meta *ResponseMeta, opts requestOptions, read func(body io.Reader) (*T, error)) (_ *T, err error) {
+	defer func(start time.Time) {
+		meta.Elapsed = time.Since(start)
+		if !opts.download {
+			c.metrics.ObserveRequest(c.name, meta.Elapsed, err)
+		}
+	}(time.Now())
+
+	if c.requestTimeout > 0 {
+		parent := req.Context()
+		ctx, cancel := context.WithTimeout(parent, c.requestTimeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+
+		defer func() {
+			if err != nil && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("%w after %s: %w", ErrRequestTimeout, c.requestTimeout, err)
+			}
+		}()
+	}
+
+	if c.signer != nil && !opts.download {
+		if err := c.signer(req); err != nil {
+			return nil, fmt.Errorf("sign request: %w", err)
+		}
+	}
+
+	if c.requestHook != nil && !opts.download {
+		c.requestHook(req)
+	}
+	start := time.Now()
 	resp, err := c.client.Do(req)
+	if c.responseHook != nil && !opts.download {
+		c.responseHook(resp, err, time.Since(start))
+	}
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var page Page
-	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+	meta.StatusCode = resp.StatusCode
+	meta.Header = make(http.Header, len(ResponseMetaHeaders))
+	for _, key := range ResponseMetaHeaders {
+		if values := resp.Header.Values(key); len(values) > 0 {
+			meta.Header[http.CanonicalHeaderKey(key)] = values
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotFound && opts.emptyOn404 {
+		return new(T), nil
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, apiErrorBodyLimit))
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       string(body),
+		}
+	}
+
+	return read(resp.Body)
+}
+
+// checkPageConsistency returns an *InconsistentPageError if the page falls within
+// the reported torrents count, but has no torrents in it.
+func checkPageConsistency(page *Page, urlOptions URLOptions) error {
+	if page.TorrentsCount == 0 || len(page.Torrents) != 0 || page.BeyondLast {
+		return nil
+	}
+
+	number, limit := page.Page, page.Limit
+	if number == 0 {
+		number = max(urlOptions.Page, 1)
+	}
+	if limit == 0 {
+		limit = urlOptions.Limit
+	}
+	if limit <= 0 || (number-1)*limit >= page.TorrentsCount {
+		return nil // Page is past the last one, so it is expected to be empty.
+	}
+
+	return &InconsistentPageError{
+		Page:          number,
+		Limit:         limit,
+		TorrentsCount: page.TorrentsCount,
+	}
+}
+
+// sortNewestFirst sorts torrents into descending ID order, unless they are already in it.
+// It reports whether the torrents had to be sorted.
+func sortNewestFirst(torrents []Torrent) bool {
+	newestFirst := func(a, b Torrent) int { return cmp.Compare(b.ID, a.ID) }
+	if slices.IsSortedFunc(torrents, newestFirst) {
+		return false
+	}
+
+	slices.SortStableFunc(torrents, newestFirst)
+	return true
+}
+
+// poll fetches a page of torrents for the stream, bounding the request by PollTimeout.
+func (c *Client) poll(ctx context.Context, streamOptions StreamOptions, urlOptions URLOptions) (*Page, error) {
+	if streamOptions.PollTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, streamOptions.PollTimeout)
+		defer cancel()
+	}
+
+	page, err := c.GetTorrents(ctx, urlOptions)
+	if err != nil {
 		return nil, err
 	}
 
-	return &page, nil
+	if streamOptions.onPoll != nil {
+		streamOptions.onPoll()
+	}
+	return page, nil
 }
 
 // TorrentStream returns a channel that will push new torrents as they are added to the EZTV API.
+// Errors, such as an *APIError, are pushed as a StreamTorrent with Err set.
 //
 // StreamOptions allow to specify LastTorrentID from which to start the stream. If LastTorrentID is 0,
-// it will do a full re-sync of all torrents for the given ImdbID.
+// it will do a full re-sync of all torrents for the given ImdbID, unless StateStore holds
+// a saved ID to resume from.
 //
 // If no ImdID is specified, it will return ErrMissingImdbID error from stream and close it.
+// An ImdbID that is not all digits after its "tt" prefix returns ErrInvalidImdbID the same way.
 //
 // If no RecheckInterval is specified, it will default to StreamRecheckInterval constant.
+//
+// If MaxDuration is specified, the stream is closed once it elapses.
+//
+// If the client was created WithSharedStreams, streams for the same ImdbID share a single poller.
 func (c *Client) TorrentStream(ctx context.Context, streamOptions StreamOptions) <-chan StreamTorrent {
+	if c.shared != nil && strings.TrimPrefix(streamOptions.ImdbID, "tt") != "" {
+		return c.shared.subscribe(ctx, c, streamOptions)
+	}
+	return c.torrentStream(ctx, streamOptions)
+}
+
+func (c *Client) torrentStream(ctx context.Context, streamOptions StreamOptions) <-chan StreamTorrent {
 	torrentsCh := make(chan StreamTorrent)
 
 	go func() {
 		defer close(torrentsCh)
 
+		if streamOptions.MaxDuration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, streamOptions.MaxDuration)
+			defer cancel()
+		}
+
 		lastTorrentID := streamOptions.LastTorrentID
 		imdbID := strings.TrimPrefix(streamOptions.ImdbID, "tt")
 		if imdbID == "" {
-			torrentsCh <- StreamTorrent{Err: ErrMissingImdbID}
+			send(ctx, torrentsCh, StreamTorrent{Err: ErrMissingImdbID})
+			return
+		}
+		if err := checkImdbID(imdbID); err != nil {
+			send(ctx, torrentsCh, StreamTorrent{Err: err})
 			return
 		}
 		recheckInterval := streamOptions.RecheckInterval
@@ -131,22 +897,89 @@ func (c *Client) TorrentStream(ctx context.Context, streamOptions StreamOptions)
 			recheckInterval = StreamRecheckInterval
 		}
 
+		state := newStreamState(streamOptions, imdbID)
+		if lastTorrentID == 0 {
+			var err error
+			lastTorrentID, err = state.load(ctx)
+			if err != nil {
+				send(ctx, torrentsCh, StreamTorrent{Err: err})
+				return
+			}
+		}
+
+		state.progress = lastTorrentID
+
+		c.logger.DebugContext(ctx, "stream started", "imdb_id", imdbID, "last_torrent_id", lastTorrentID)
 		if lastTorrentID == 0 { // Full re-sync.
-			lastTorrentID = c.fullStreamResync(ctx, torrentsCh, imdbID)
+			for {
+				var err error
+				lastTorrentID, err = c.fullStreamResync(ctx, torrentsCh, state, imdbID, streamOptions)
+				if err != nil && !send(ctx, torrentsCh, StreamTorrent{Err: err}) {
+					return
+				}
+				// A re-sync that failed before it went past any torrent is tried again after the recheck
+				// interval. This is always the case with StrictChronological, which emits nothing until
+				// every page was fetched.
+				if err == nil || lastTorrentID > 0 {
+					break
+				}
+				if sleep(ctx, withJitter(recheckInterval, streamOptions.Jitter)) != nil {
+					return
+				}
+			}
+			if !c.advance(ctx, torrentsCh, state, lastTorrentID) {
+				return
+			}
 		}
 
+		var (
+			pending   []Torrent
+			debounceC <-chan time.Time
+		)
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(recheckInterval):
-				page, err := c.GetTorrents(ctx, URLOptions{
+			case <-debounceC:
+				c.logger.DebugContext(ctx, "emitting debounced torrents", "imdb_id", imdbID, "torrents", len(pending))
+				for _, torrent := range pending {
+					if !c.emit(ctx, torrentsCh, state, torrent) {
+						return
+					}
+					if !c.advance(ctx, torrentsCh, state, torrent.ID) {
+						return
+					}
+				}
+				pending, debounceC = nil, nil
+			case <-time.After(withJitter(recheckInterval, streamOptions.Jitter)):
+				c.logger.DebugContext(ctx, "rechecking stream", "imdb_id", imdbID, "last_torrent_id", lastTorrentID)
+				limit := 1
+				if streamOptions.FullSnapshotMode {
+					limit = MaxEZTVAPILimit
+				}
+				page, err := c.poll(ctx, streamOptions, URLOptions{
 					ImdbID: imdbID,
 					Page:   1,
-					Limit:  1,
+					Limit:  limit,
 				})
 				if err != nil {
-					torrentsCh <- StreamTorrent{Err: err}
+					if !send(ctx, torrentsCh, StreamTorrent{Err: err}) {
+						return
+					}
+					continue
+				}
+
+				if streamOptions.FullSnapshotMode {
+					for _, torrent := range page.Torrents {
+						if !state.pace(ctx) || !send(ctx, torrentsCh, StreamTorrent{Torrent: torrent, Snapshot: true}) {
+							return
+						}
+						c.recordEmitted(torrent)
+						lastTorrentID = max(lastTorrentID, torrent.ID)
+					}
+					if !c.advance(ctx, torrentsCh, state, lastTorrentID) {
+						return
+					}
 					continue
 				}
 
@@ -154,11 +987,33 @@ func (c *Client) TorrentStream(ctx context.Context, streamOptions StreamOptions)
 					continue
 				}
 
-				lastTorrentID = page.Torrents[0].ID
+				newTorrents, err := c.pollNewer(ctx, streamOptions, imdbID, lastTorrentID)
+				if err != nil {
+					if !send(ctx, torrentsCh, StreamTorrent{Err: err}) {
+						return
+					}
+					continue
+				}
+				if len(newTorrents) == 0 {
+					continue
+				}
 
-				torrentsCh <- StreamTorrent{
-					Torrent: page.Torrents[0],
-					Err:     nil,
+				if streamOptions.Debounce > 0 {
+					pending = append(pending, newTorrents...)
+					debounceC = time.After(streamOptions.Debounce)
+					lastTorrentID = max(lastTorrentID, newTorrents[len(newTorrents)-1].ID)
+					continue
+				}
+
+				c.logger.DebugContext(ctx, "emitting new torrents", "imdb_id", imdbID, "torrents", len(newTorrents))
+				for _, torrent := range newTorrents {
+					if !c.emit(ctx, torrentsCh, state, torrent) {
+						return
+					}
+					lastTorrentID = torrent.ID
+					if !c.advance(ctx, torrentsCh, state, lastTorrentID) {
+						return
+					}
 				}
 			}
 		}
@@ -167,44 +1022,221 @@ func (c *Client) TorrentStream(ctx context.Context, streamOptions StreamOptions)
 	return torrentsCh
 }
 
-func (c *Client) fullStreamResync(ctx context.Context, torrentsCh chan<- StreamTorrent, imdbID string) int {
-	// Fetch first page to figure out the total number of torrents.
-	// And then re-sync backwards.
-	page, err := c.GetTorrents(ctx, URLOptions{
-		ImdbID: imdbID,
-		Page:   1,
-		Limit:  1,
-	})
-	if err != nil {
-		torrentsCh <- StreamTorrent{Err: err}
-		return 0
+// pollNewer pages through the torrents of the show that are newer than lastTorrentID
+// and returns them in ascending ID order.
+func (c *Client) pollNewer(ctx context.Context, streamOptions StreamOptions, imdbID string, lastTorrentID int) ([]Torrent, error) {
+	var torrents []Torrent
+	for i := 1; ; i++ {
+		page, err := c.poll(ctx, streamOptions, URLOptions{
+			ImdbID: imdbID,
+			Page:   i,
+			Limit:  MaxEZTVAPILimit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		done := len(page.Torrents) == 0 || i >= page.lastPage(MaxEZTVAPILimit)
+		for _, torrent := range page.Torrents {
+			if torrent.ID <= lastTorrentID {
+				done = true
+				break
+			}
+			torrents = append(torrents, torrent)
+		}
+		if done {
+			break
+		}
+	}
+
+	slices.Reverse(torrents)
+	return torrents, nil
+}
+
+func (c *Client) fullStreamResync(ctx context.Context, torrentsCh chan<- StreamTorrent, state *streamState, imdbID string, streamOptions StreamOptions) (int, error) {
+	torrentsCount := streamOptions.KnownTorrentCount
+	fetched := false
+	if torrentsCount <= 0 {
+		// Fetch first page to figure out the total number of torrents.
+		// And then re-sync backwards.
+		page, err := c.poll(ctx, streamOptions, URLOptions{
+			ImdbID: imdbID,
+			Page:   1,
+			Limit:  1,
+		})
+		if err != nil {
+			return 0, err
+		}
+		torrentsCount = page.TorrentsCount
+		fetched = true
 	}
 
-	if page.TorrentsCount == 0 { // Nothing to re-sync.
-		return 0
+	if torrentsCount == 0 { // Nothing to re-sync.
+		return 0, nil
 	}
-	pages := int(math.Ceil(float64(page.TorrentsCount) / MaxEZTVAPILimit))
-	lastTorrentID := 0
+	limit := MaxEZTVAPILimit
+	if streamOptions.LowMemory {
+		limit = LowMemoryResyncLimit
+	}
+	pages := pageCount(torrentsCount, limit)
+	c.logger.DebugContext(ctx, "full re-sync started", "imdb_id", imdbID, "torrents_count", torrentsCount, "pages", pages)
+	lastTorrentID, newestBuffered := 0, 0
+	var buffered []Torrent
+	cutoff := time.Now().Add(-streamOptions.MaxAge)
+	tooOld := func(t Torrent) bool {
+		if t.DateReleasedUnix == 0 {
+			return !streamOptions.IncludeUnknownDates
+		}
+		return t.ReleasedAt().Before(cutoff)
+	}
+
+	// Up to ResyncConcurrency pages, from page i downwards, are being fetched at any time.
+	// Returning cancels the ones still in flight.
+	concurrency := max(streamOptions.ResyncConcurrency, 1)
+	var fetches []*pageFetch
+	defer func() {
+		for _, fetch := range fetches {
+			fetch.cancel()
+		}
+	}()
+	next := pages
 	for i := pages; i > 0; i-- { // Re-sync backwards.
+		for ; next > 0 && next > i-concurrency; next-- {
+			if fetched && streamOptions.ResyncDelay > 0 {
+				if err := state.sleep(ctx, streamOptions.ResyncDelay); err != nil {
+					return lastTorrentID, err
+				}
+			}
+			fetched = true
+
+			fetches = append(fetches, c.fetchPage(ctx, streamOptions, URLOptions{
+				ImdbID: imdbID,
+				Page:   next,
+				Limit:  limit,
+			}))
+		}
+
+		var result pageResult
+		select {
+		case <-ctx.Done():
+			return lastTorrentID, ctx.Err()
+		case result = <-fetches[0].resultCh:
+			fetches[0].cancel()
+			fetches = fetches[1:]
+		}
+		page, err := result.page, result.err
+		if err != nil {
+			return lastTorrentID, err
+		}
+
+		if i == pages && page.Limit > 0 && page.Limit < limit {
+			// The API caps the limit lower than requested, so the pages computed so far
+			// do not line up with what it returns. Start over from its actual last page.
+			for _, fetch := range fetches {
+				fetch.cancel()
+			}
+			fetches = nil
+			limit = page.Limit
+			pages = pageCount(torrentsCount, limit)
+			i, next = pages+1, pages
+			c.logger.DebugContext(ctx, "full re-sync restarted with the api limit", "imdb_id", imdbID, "limit", limit, "pages", pages)
+			continue
+		}
+		c.logger.DebugContext(ctx, "full re-sync fetched page", "imdb_id", imdbID, "page", i, "pages", pages, "torrents", len(page.Torrents))
+
+		slices.Reverse(page.Torrents)
+		newest := 0
+		if len(page.Torrents) > 0 {
+			// Torrents skipped for their age still move the stream past them.
+			newest = page.Torrents[len(page.Torrents)-1].ID
+		}
+		if streamOptions.MaxAge > 0 {
+			page.Torrents = slices.DeleteFunc(page.Torrents, tooOld)
+		}
+		if streamOptions.StrictChronological {
+			buffered = append(buffered, page.Torrents...)
+			newestBuffered = max(newestBuffered, newest)
+			continue
+		}
+		for _, torrent := range page.Torrents {
+			if !c.emit(ctx, torrentsCh, state, torrent) {
+				return lastTorrentID, ctx.Err()
+			}
+			if !c.advance(ctx, torrentsCh, state, torrent.ID) {
+				return lastTorrentID, ctx.Err()
+			}
+		}
+		lastTorrentID = max(lastTorrentID, newest)
+		if !c.advance(ctx, torrentsCh, state, lastTorrentID) {
+			return lastTorrentID, ctx.Err()
+		}
+	}
+
+	slices.SortStableFunc(buffered, func(a, b Torrent) int {
+		return a.CompareTo(b, SortKeyDate, SortKeyID)
+	})
+	for _, torrent := range buffered {
+		if !c.emit(ctx, torrentsCh, state, torrent) {
+			return lastTorrentID, ctx.Err()
+		}
+	}
+
+	lastTorrentID = max(lastTorrentID, newestBuffered)
+	c.logger.DebugContext(ctx, "full re-sync finished", "imdb_id", imdbID, "last_torrent_id", lastTorrentID)
+	return lastTorrentID, nil
+}
+
+// pageFetch is a page being fetched in the background by fetchPage.
+type pageFetch struct {
+	// resultCh receives the result once the fetch is done. It is buffered,
+	// so the fetch never blocks, even if the result is never received.
+	resultCh <-chan pageResult
+	// cancel aborts the fetch if it is still in flight.
+	cancel context.CancelFunc
+}
+
+type pageResult struct {
+	page *Page
+	err  error
+}
+
+// fetchPage polls the page in a new goroutine.
+func (c *Client) fetchPage(ctx context.Context, streamOptions StreamOptions, urlOptions URLOptions) *pageFetch {
+	ctx, cancel := context.WithCancel(ctx)
+	resultCh := make(chan pageResult, 1)
+	go func() {
+		page, err := c.poll(ctx, streamOptions, urlOptions)
+		resultCh <- pageResult{page: page, err: err}
+	}()
+	return &pageFetch{resultCh: resultCh, cancel: cancel}
+}
+
+// forEachPage walks every page of the show from newest to oldest, calling fn for each of them.
+// Walking stops early when fn returns false.
+//
+// If maxPages is positive, at most that many pages are walked and ErrSearchTruncated
+// is returned if there were more pages left.
+func (c *Client) forEachPage(ctx context.Context, imdbID string, maxPages int, fn func(page *Page) bool) error {
+	for i := 1; ; i++ {
+		if maxPages > 0 && i > maxPages {
+			return ErrSearchTruncated
+		}
+
 		page, err := c.GetTorrents(ctx, URLOptions{
 			ImdbID: imdbID,
 			Page:   i,
 			Limit:  MaxEZTVAPILimit,
 		})
 		if err != nil {
-			torrentsCh <- StreamTorrent{Err: err}
-			return lastTorrentID
+			return err
 		}
 
-		slices.Reverse(page.Torrents)
-		for _, torrent := range page.Torrents {
-			torrentsCh <- StreamTorrent{
-				Torrent: torrent,
-				Err:     nil,
-			}
-			lastTorrentID = torrent.ID
+		if len(page.Torrents) == 0 || !fn(page) {
+			return nil
 		}
-	}
 
-	return lastTorrentID
+		if i >= page.lastPage(MaxEZTVAPILimit) {
+			return nil
+		}
+	}
 }
@@ -0,0 +1,1064 @@
+package eztv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// testClient returns a client of a test server that handles every request with h.
+func testClient(t *testing.T, h http.Handler, ops ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return New(append([]Option{WithBaseURL(srv.URL)}, ops...)...)
+}
+
+// fakeAPI serves the get-torrents endpoint like the EZTV API: torrents newest first,
+// paginated by the page and limit query parameters, with a default limit of 30.
+type fakeAPI struct {
+	mu       sync.Mutex
+	torrents []Torrent
+	// maxLimit caps the limit of the responses, like the API does at MaxEZTVAPILimit.
+	maxLimit int
+	queries  []url.Values
+}
+
+// newFakeAPI returns a fakeAPI serving torrents with the IDs 1 to n.
+func newFakeAPI(n int) *fakeAPI {
+	return &fakeAPI{torrents: torrentsWithIDs(1, n), maxLimit: MaxEZTVAPILimit}
+}
+
+// torrentsWithIDs returns torrents with the IDs from to to, newest first.
+func torrentsWithIDs(from, to int) []Torrent {
+	var torrents []Torrent
+	for id := to; id >= from; id-- {
+		torrents = append(torrents, Torrent{ID: id, Hash: "hash" + strconv.Itoa(id), DateReleasedUnix: 1_700_000_000 + id})
+	}
+	return torrents
+}
+
+// ids returns the IDs of the torrents.
+func ids(torrents []Torrent) []int {
+	ids := make([]int, 0, len(torrents))
+	for _, torrent := range torrents {
+		ids = append(ids, torrent.ID)
+	}
+	return ids
+}
+
+// add publishes new torrents, which must be newer than the ones already served.
+func (f *fakeAPI) add(torrents ...Torrent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, torrent := range torrents {
+		f.torrents = append([]Torrent{torrent}, f.torrents...)
+	}
+}
+
+// requests returns the queries of every request served so far.
+func (f *fakeAPI) requests() []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]url.Values(nil), f.queries...)
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q := r.URL.Query()
+	f.queries = append(f.queries, q)
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	page = max(page, 1)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 30
+	}
+	limit = min(limit, f.maxLimit)
+
+	var torrents []Torrent
+	if imdbID := q.Get("imdb_id"); imdbID != "" {
+		for _, torrent := range f.torrents {
+			if torrent.ImdbID == "" || strings.TrimPrefix(torrent.ImdbID, "tt") == imdbID {
+				torrents = append(torrents, torrent)
+			}
+		}
+	} else {
+		torrents = f.torrents
+	}
+
+	start, end := min((page-1)*limit, len(torrents)), min(page*limit, len(torrents))
+	json.NewEncoder(w).Encode(Page{
+		ImdbID:        q.Get("imdb_id"),
+		TorrentsCount: len(torrents),
+		Limit:         limit,
+		Page:          page,
+		Torrents:      append([]Torrent{}, torrents[start:end]...),
+	})
+}
+
+func TestWithVerifyOrdering(t *testing.T) {
+	outOfOrder := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Page{TorrentsCount: 3, Limit: 3, Page: 1, Torrents: []Torrent{{ID: 2}, {ID: 3}, {ID: 1}}})
+	})
+
+	logger, buf := newTestLogger(slog.LevelWarn)
+	c := testClient(t, outOfOrder, WithVerifyOrdering(), WithLogger(logger))
+	page, err := c.GetTorrents(context.Background(), URLOptions{Page: 1, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(page.Torrents); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Fatalf("got torrents %v, want [3 2 1]", got)
+	}
+	if out := buf.String(); !strings.Contains(out, `level=WARN msg="reordered torrents returned out of order" query="limit=3&page=1"`) {
+		t.Fatalf("missing warning in log %q", out)
+	}
+
+	c = testClient(t, outOfOrder)
+	page, err = c.GetTorrents(context.Background(), URLOptions{Page: 1, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(page.Torrents); !slices.Equal(got, []int{2, 3, 1}) {
+		t.Fatalf("got torrents %v, want the API order [2 3 1] without WithVerifyOrdering", got)
+	}
+}
+
+func TestAllTorrentsFrom(t *testing.T) {
+	api := newFakeAPI(350)
+	c := testClient(t, api)
+
+	var got []int
+	for torrent, err := range c.AllTorrentsFrom(context.Background(), "tt1", 3) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, torrent.ID)
+	}
+	if want := ids(torrentsWithIDs(1, 150)); !slices.Equal(got, want) {
+		t.Fatalf("got %d torrents from %v, want 150 to 1", len(got), got[:min(len(got), 3)])
+	}
+	var pages []string
+	for _, q := range api.requests() {
+		pages = append(pages, q.Get("page"))
+	}
+	if !slices.Equal(pages, []string{"3", "4"}) {
+		t.Fatalf("fetched pages %v, want [3 4]", pages)
+	}
+
+	for torrent, err := range c.AllTorrentsFrom(context.Background(), "tt1", 5) {
+		t.Fatalf("got %+v, %v past the last page", torrent, err)
+	}
+}
+
+func TestAllTorrentsFromInvalidPage(t *testing.T) {
+	c := testClient(t, newFakeAPI(1))
+	for _, startPage := range []int{0, -1} {
+		var errs []error
+		for _, err := range c.AllTorrentsFrom(context.Background(), "tt1", startPage) {
+			errs = append(errs, err)
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], ErrInvalidPage) {
+			t.Fatalf("start page %d: got %v, want ErrInvalidPage", startPage, errs)
+		}
+	}
+}
+
+func TestGetTorrentIDsAndMagnets(t *testing.T) {
+	const hash = "ABCDEF0123456789ABCDEF0123456789ABCDEF01"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Some mirrors serve the first page for pages past the last one.
+		json.NewEncoder(w).Encode(Page{TorrentsCount: 3, Limit: 2, Page: 1, Torrents: []Torrent{
+			{ID: 1, Title: "old", MagnetURL: "magnet:?xt=urn:btih:" + hash + "&dn=old"},
+			{ID: 2, Title: "new", MagnetURL: "magnet:?xt=urn:btih:" + hash + "&dn=new"},
+		}})
+	})
+
+	c := testClient(t, handler, WithCanonicalHashes(), WithVerifyOrdering())
+	magnets, err := c.GetTorrentIDsAndMagnets(context.Background(), URLOptions{Page: 1, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TorrentMagnet{
+		{ID: 2, Magnet: "magnet:?xt=urn:btih:" + strings.ToLower(hash) + "&dn=new"},
+		{ID: 1, Magnet: "magnet:?xt=urn:btih:" + strings.ToLower(hash) + "&dn=old"},
+	}
+	if !slices.Equal(magnets, want) {
+		t.Fatalf("got %v, want %v", magnets, want)
+	}
+
+	magnets, err = c.GetTorrentIDsAndMagnets(context.Background(), URLOptions{Page: 3, Limit: 2})
+	if err != nil || len(magnets) != 0 {
+		t.Fatalf("got %v, %v past the last page, want no magnets", magnets, err)
+	}
+
+	for _, urlOptions := range []URLOptions{{MinSeeds: 1}, {Sort: SortSeedsDesc}} {
+		if _, err := c.GetTorrentIDsAndMagnets(context.Background(), urlOptions); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%+v: got %v, want ErrUnsupported", urlOptions, err)
+		}
+	}
+}
+
+func TestGetTorrentIDsAndMagnetsInconsistentPage(t *testing.T) {
+	c := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Page{TorrentsCount: 5, Limit: 2, Page: 1, Torrents: []Torrent{}})
+	}))
+	var inconsistent *InconsistentPageError
+	if _, err := c.GetTorrentIDsAndMagnets(context.Background(), URLOptions{Page: 1, Limit: 2}); !errors.As(err, &inconsistent) {
+		t.Fatalf("got %v, want an *InconsistentPageError", err)
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	if _, err := NewStrict(WithBaseURL("https://eztv.example/api"), WithMirrors("https://a.example/api", "https://b.example/api")); err != nil {
+		t.Fatalf("valid configuration was rejected: %v", err)
+	}
+
+	tests := map[string][]Option{
+		"nil http client":         {WithHTTPClient(nil)},
+		"nil http client, record": {WithHTTPClient(nil), WithRecorder(t.TempDir())},
+		"nil http client, replay": {WithHTTPClient(nil), WithReplayer(t.TempDir())},
+		"unparsable base url":     {WithBaseURL("://eztv")},
+		"relative base url":       {WithBaseURL("/api")},
+		"no mirrors":              {WithMirrors()},
+		"duplicate mirrors":       {WithMirrors("https://eztv.example/api", "https://EZTV.example/api/")},
+		"negative decode retries": {WithDecodeRetry(-1)},
+		"negative retries":        {WithRetry(-1, time.Second)},
+		"negative timeout":        {WithRequestTimeout(-time.Second)},
+		"zero tracker timeout":    {WithTrackerTimeout(0)},
+		"nil metrics":             {WithMetrics(nil)},
+		"nil tracker provider":    {WithTrackerProvider(nil)},
+		"negative search pages":   {WithMaxSearchPages(-1)},
+		"record and replay":       {WithRecorder(t.TempDir()), WithReplayer(t.TempDir())},
+	}
+	for name, ops := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewStrict(ops...)
+			if err == nil || c != nil {
+				t.Fatalf("got %v, %v, want an error", c, err)
+			}
+			if !strings.HasPrefix(err.Error(), "invalid client configuration: ") {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestWithStrictInit(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api, WithStrictInit())
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatalf("valid configuration failed a request: %v", err)
+	}
+
+	logger, buf := newTestLogger(slog.LevelError)
+	c = testClient(t, api, WithStrictInit(), WithRequestTimeout(-time.Second), WithLogger(logger))
+	if !strings.Contains(buf.String(), "rejected client configuration") {
+		t.Fatalf("the invalid configuration was not logged: %q", buf.String())
+	}
+	n := len(api.requests())
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err == nil || !strings.HasPrefix(err.Error(), "invalid client configuration: ") {
+		t.Fatalf("got %v, want the invalid configuration", err)
+	}
+	if got := len(api.requests()); got != n {
+		t.Fatalf("got %d requests with an invalid configuration, want none", got-n)
+	}
+
+	// Without WithStrictInit, New stays lenient.
+	c = testClient(t, api, WithRequestTimeout(-time.Second))
+	if c.initErr != nil {
+		t.Fatalf("New without WithStrictInit validated the configuration: %v", c.initErr)
+	}
+}
+
+// clientsMetrics records the client name of every observed request.
+type clientsMetrics struct {
+	noopMetrics
+
+	mu      sync.Mutex
+	clients []string
+}
+
+func (m *clientsMetrics) ObserveRequest(client string, _ time.Duration, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients = append(m.clients, client)
+}
+
+func TestWithClientName(t *testing.T) {
+	if name := New().Name(); name != "" {
+		t.Fatalf("got name %q, want an unnamed client by default", name)
+	}
+
+	logger, buf := newTestLogger(slog.LevelDebug)
+	metrics := &clientsMetrics{}
+	c := testClient(t, newFakeAPI(3), WithClientName("tenant"), WithLogger(logger), WithMetrics(metrics))
+	if name := c.Name(); name != "tenant" {
+		t.Fatalf("got name %q, want tenant", name)
+	}
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `msg="fetched torrents" client=tenant `) {
+		t.Fatalf("client name missing from log %q", out)
+	}
+	if !slices.Equal(metrics.clients, []string{"tenant"}) {
+		t.Fatalf("got metrics of clients %v, want [tenant]", metrics.clients)
+	}
+}
+
+func TestWithTreat404AsEmpty(t *testing.T) {
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "show not found", http.StatusNotFound)
+	})
+
+	_, err := testClient(t, notFound).GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 1})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("got %v, want an *APIError with status 404 by default", err)
+	}
+
+	page, err := testClient(t, notFound, WithTreat404AsEmpty()).GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Torrents) != 0 || page.TorrentsCount != 0 {
+		t.Fatalf("got %+v, want an empty page", page)
+	}
+}
+
+func TestGetTorrentsInconsistentPage(t *testing.T) {
+	c := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Page{TorrentsCount: 40, Limit: 30, Page: 2, Torrents: []Torrent{}})
+	}))
+
+	_, err := c.GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 2, Limit: 30})
+	var inconsistent *InconsistentPageError
+	if !errors.As(err, &inconsistent) {
+		t.Fatalf("got %v, want an *InconsistentPageError", err)
+	}
+	if want := (InconsistentPageError{Page: 2, Limit: 30, TorrentsCount: 40}); *inconsistent != want {
+		t.Fatalf("got %+v, want %+v", *inconsistent, want)
+	}
+
+	// Pagination reports the inconsistent page instead of ending as if the show had no more torrents.
+	next, stop := iter.Pull2(c.AllTorrentsFrom(context.Background(), "tt1", 2))
+	defer stop()
+	if torrent, err, _ := next(); !errors.As(err, &inconsistent) {
+		t.Fatalf("got %+v, %v, want an *InconsistentPageError", torrent, err)
+	}
+
+	// Pages past the last one are expected to be empty.
+	c = testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Page{TorrentsCount: 40, Limit: 30, Page: 3, Torrents: []Torrent{}})
+	}))
+	if _, err := c.GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 3, Limit: 30}); err != nil {
+		t.Fatalf("got %v past the last page", err)
+	}
+}
+
+func TestWithDecodeRetry(t *testing.T) {
+	// truncatingAPI cuts the body of its first response short.
+	truncatingAPI := func() (http.Handler, *int) {
+		var mu sync.Mutex
+		requests := 0
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			n := requests
+			mu.Unlock()
+
+			body, _ := json.Marshal(Page{TorrentsCount: 1, Limit: 30, Page: 1, Torrents: []Torrent{{ID: 1}}})
+			if n == 1 {
+				body = body[:len(body)/2]
+			}
+			w.Write(body)
+		}), &requests
+	}
+
+	api, requests := truncatingAPI()
+	page, err := testClient(t, api, WithDecodeRetry(1)).GetTorrents(context.Background(), URLOptions{Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(page.Torrents); !slices.Equal(got, []int{1}) || *requests != 2 {
+		t.Fatalf("got torrents %v after %d requests, want [1] after 2", got, *requests)
+	}
+
+	api, _ = truncatingAPI()
+	if _, err := testClient(t, api).GetTorrents(context.Background(), URLOptions{Page: 1}); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("got %v, want io.ErrUnexpectedEOF without WithDecodeRetry", err)
+	}
+}
+
+func TestGetTorrentsWithQuery(t *testing.T) {
+	c := testClient(t, newFakeAPI(3))
+	tests := []struct {
+		urlOptions URLOptions
+		want       url.Values
+	}{
+		{URLOptions{Page: 2, Limit: 500, ImdbID: "tt0123"}, url.Values{"page": {"2"}, "limit": {"100"}, "imdb_id": {"0123"}}},
+		{URLOptions{Page: 1, Limit: -5, ImdbID: "0123"}, url.Values{"page": {"1"}, "imdb_id": {"0123"}}},
+		{URLOptions{}, url.Values{}},
+	}
+	for _, tt := range tests {
+		_, query, err := c.GetTorrentsWithQuery(context.Background(), tt.urlOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !maps.EqualFunc(query, tt.want, slices.Equal) {
+			t.Errorf("%+v: got query %v, want %v", tt.urlOptions, query, tt.want)
+		}
+	}
+
+	if _, query, err := c.GetTorrentsWithQuery(context.Background(), URLOptions{ImdbID: "tt12a"}); err == nil || query != nil {
+		t.Fatalf("got %v, %v for a request that could not be built, want an error without a query", query, err)
+	}
+}
+
+func TestWithBackfillImdbID(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit, torrents: []Torrent{{ID: 2}, {ID: 1, ImdbID: "tt1"}}}
+
+	page, err := testClient(t, api, WithBackfillImdbID()).GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := []string{page.Torrents[0].ImdbID, page.Torrents[1].ImdbID}; !slices.Equal(got, []string{"1", "tt1"}) {
+		t.Fatalf("got imdb ids %q, want only the blank one filled in", got)
+	}
+
+	// Pages not requested for a show have no ImdbID to fill in.
+	page, err = testClient(t, api, WithBackfillImdbID()).GetTorrents(context.Background(), URLOptions{Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := page.Torrents[0].ImdbID; got != "" {
+		t.Fatalf("got imdb id %q for the global feed, want it left blank", got)
+	}
+
+	page, err = testClient(t, api).GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := page.Torrents[0].ImdbID; got != "" {
+		t.Fatalf("got imdb id %q without WithBackfillImdbID, want it left blank", got)
+	}
+}
+
+func TestWithResultTransforms(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit, torrents: []Torrent{{ID: 1, Title: "show", Hash: "ABCDEF0123456789ABCDEF0123456789ABCDEF01"}}}
+	var hashes []string
+	c := testClient(t, api, WithCanonicalHashes(),
+		WithResultTransforms(func(t *Torrent) {
+			hashes = append(hashes, t.Hash)
+			t.Title += " first"
+		}),
+		WithResultTransforms(func(t *Torrent) { t.Title += " second" }, func(t *Torrent) { t.Title = strings.ToUpper(t.Title) }),
+	)
+
+	page, err := c.GetTorrents(context.Background(), URLOptions{Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title := page.Torrents[0].Title; title != "SHOW FIRST SECOND" {
+		t.Fatalf("got title %q, want the transforms applied in order", title)
+	}
+	if !slices.Equal(hashes, []string{"abcdef0123456789abcdef0123456789abcdef01"}) {
+		t.Fatalf("transforms saw hashes %v, want them canonicalized first", hashes)
+	}
+
+	// MinSeeds and Sort go by the transformed torrents.
+	api = &fakeAPI{maxLimit: MaxEZTVAPILimit, torrents: []Torrent{{ID: 3, Seeds: 5}, {ID: 2, Seeds: 1}, {ID: 1, Seeds: 1}}}
+	c = testClient(t, api, WithResultTransforms(func(t *Torrent) {
+		if t.ID == 1 {
+			t.Seeds = 10
+		}
+	}))
+	page, err = c.GetTorrents(context.Background(), URLOptions{Page: 1, MinSeeds: 2, Sort: SortSeedsDesc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(page.Torrents); !slices.Equal(got, []int{1, 3}) {
+		t.Fatalf("got %v, want [1 3] filtered and sorted by the transformed seeds", got)
+	}
+}
+
+func TestGetTorrentsComprehensive(t *testing.T) {
+	c := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := Page{Limit: MaxEZTVAPILimit, Page: 1}
+		if r.URL.Query().Get("imdb_id") == "1" {
+			// Torrent 3 is not indexed under the show yet.
+			page.Torrents = []Torrent{{ID: 2, ImdbID: "1"}, {ID: 1, ImdbID: "1"}}
+		} else {
+			page.Torrents = []Torrent{{ID: 4, ImdbID: "2"}, {ID: 3, ImdbID: "tt1"}, {ID: 2, ImdbID: "1"}}
+		}
+		page.TorrentsCount = len(page.Torrents)
+		json.NewEncoder(w).Encode(page)
+	}))
+
+	torrents, err := c.GetTorrentsComprehensive(context.Background(), "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(torrents); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Fatalf("got torrents %v, want [3 2 1]", got)
+	}
+}
+
+func TestGetTorrentsWithResponse(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("CF-Cache-Status", "HIT")
+		w.Header().Set("Age", "42")
+		w.Header().Set("X-Internal", "secret")
+		api.ServeHTTP(w, r)
+	}))
+
+	page, meta, err := c.GetTorrentsWithResponse(context.Background(), URLOptions{Page: 1, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Torrents) != 2 {
+		t.Fatalf("got %d torrents, want 2", len(page.Torrents))
+	}
+	if meta.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", meta.StatusCode)
+	}
+	if got := meta.Header.Get("CF-Cache-Status"); got != "HIT" {
+		t.Fatalf("got CF-Cache-Status %q, want HIT", got)
+	}
+	if got := meta.Header.Get("Age"); got != "42" {
+		t.Fatalf("got Age %q, want 42", got)
+	}
+	if got := meta.Header.Get("X-Internal"); got != "" {
+		t.Fatalf("got X-Internal %q, want headers not in ResponseMetaHeaders left out", got)
+	}
+	if got := meta.Query.Encode(); got != "limit=2&page=1" {
+		t.Fatalf("got query %q, want limit=2&page=1", got)
+	}
+
+	c = testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	_, meta, err = c.GetTorrentsWithResponse(context.Background(), URLOptions{Page: 1})
+	if err == nil || meta == nil || meta.StatusCode != http.StatusTooManyRequests || meta.Header.Get("Retry-After") != "30" {
+		t.Fatalf("got %+v, %v, want the meta of the failed response", meta, err)
+	}
+}
+
+func TestWithRequestSigner(t *testing.T) {
+	key := []byte("gateway secret")
+	sign := func(query string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(query))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	api := newFakeAPI(3)
+	var served int
+	gateway := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		if got := r.Header.Get("X-Signature"); !hmac.Equal([]byte(got), []byte(sign(r.URL.RawQuery))) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+
+	var signed []string
+	c := testClient(t, gateway, WithRequestSigner(func(req *http.Request) error {
+		signed = append(signed, req.URL.RawQuery)
+		req.Header.Set("X-Signature", sign(req.URL.RawQuery))
+		return nil
+	}))
+	if _, err := c.GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(signed, []string{"imdb_id=1&page=1"}) {
+		t.Fatalf("signer was called with %v, want once with the request", signed)
+	}
+
+	if _, err := testClient(t, gateway).GetTorrents(context.Background(), URLOptions{Page: 1}); err == nil {
+		t.Fatal("unsigned request was accepted")
+	}
+
+	served = 0
+	c = testClient(t, gateway, WithRequestSigner(func(*http.Request) error { return errors.New("no key") }))
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err == nil || served != 0 {
+		t.Fatalf("got %v after %d requests, want the signing error without sending the request", err, served)
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		newFakeAPI(1).ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(WithBaseURL(srv.URL + "/api/"))
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetTorrentIDsAndMagnets(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(paths, []string{"/api/get-torrents", "/api/get-torrents"}) {
+		t.Fatalf("requested paths %v, want the base URL's", paths)
+	}
+}
+
+func TestGetTorrentsLimitClamping(t *testing.T) {
+	api := newFakeAPI(150)
+	c := testClient(t, api)
+	tests := []struct {
+		limit     int
+		wantQuery string
+		wantLen   int
+	}{
+		{-5, "", 30},
+		{0, "", 30},
+		{1, "1", 1},
+		{100, "100", 100},
+		{101, "100", 100},
+	}
+	for _, tt := range tests {
+		page, query, err := c.GetTorrentsWithQuery(context.Background(), URLOptions{Page: 1, Limit: tt.limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := query.Get("limit"); got != tt.wantQuery || len(page.Torrents) != tt.wantLen {
+			t.Errorf("limit %d: sent limit %q and got %d torrents, want %q and %d", tt.limit, got, len(page.Torrents), tt.wantQuery, tt.wantLen)
+		}
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api, WithRateLimit(20, 1))
+
+	start := time.Now()
+	for range 5 {
+		if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst allows the first request right away, and every other one waits 50ms.
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("made 5 requests in %s, want at least 200ms at 20 requests per second", elapsed)
+	}
+
+	slow := testClient(t, api, WithRateLimit(rate.Every(time.Hour), 1))
+	if _, err := slow.GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start, before := time.Now(), len(api.requests())
+	if _, err := slow.GetTorrents(ctx, URLOptions{Page: 1}); err == nil {
+		t.Fatal("request succeeded, although it was not allowed before the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || len(api.requests()) != before {
+		t.Fatalf("failed after %s and %d requests, want right away without a request", elapsed, len(api.requests())-before)
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	api := newFakeAPI(3)
+	var slowRequests atomic.Int32
+	slowRequests.Store(1)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slowRequests.Add(-1) >= 0 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	_, err := testClient(t, slow, WithRequestTimeout(50*time.Millisecond)).GetTorrents(ctx, URLOptions{Page: 1})
+	if !errors.Is(err, ErrRequestTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want ErrRequestTimeout wrapping context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("timed out after %s, want 50ms", elapsed)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("the request timeout cancelled the parent context")
+	}
+
+	// Every attempt gets its own timeout.
+	slowRequests.Store(1)
+	if _, err := testClient(t, slow, WithRequestTimeout(50*time.Millisecond), WithRetry(2, time.Millisecond)).GetTorrents(ctx, URLOptions{Page: 1}); err != nil {
+		t.Fatalf("retry after the timed out attempt failed: %v", err)
+	}
+
+	// The parent context running out first is not a request timeout.
+	for name, parent := range map[string]func() (context.Context, context.CancelFunc){
+		"cancelled": func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+			return ctx, cancel
+		},
+		"deadline": func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 50*time.Millisecond)
+		},
+	} {
+		slowRequests.Store(1)
+		ctx, cancel := parent()
+		_, err := testClient(t, slow, WithRequestTimeout(time.Hour)).GetTorrents(ctx, URLOptions{Page: 1})
+		cancel()
+		if err == nil || errors.Is(err, ErrRequestTimeout) || !errors.Is(err, ctx.Err()) {
+			t.Fatalf("%s parent: got %v, want the %v of the parent context", name, err, ctx.Err())
+		}
+	}
+}
+
+func TestGetTorrentsMinSeeds(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+	api.add(Torrent{ID: 1, Seeds: 0}, Torrent{ID: 2, Seeds: 9}, Torrent{ID: 3, Seeds: 10}, Torrent{ID: 4, Seeds: 250})
+	c := testClient(t, api)
+
+	for minSeeds, want := range map[int][]int{-1: {4, 3, 2, 1}, 0: {4, 3, 2, 1}, 1: {4, 3, 2}, 10: {4, 3}, 251: {}} {
+		page, err := c.GetTorrents(context.Background(), URLOptions{Page: 1, MinSeeds: minSeeds})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(page.Torrents); !slices.Equal(got, want) {
+			t.Errorf("min seeds %d: got %v, want %v", minSeeds, got, want)
+		}
+		// Filtering is done by the client, so the count is the one reported by the API.
+		if page.TorrentsCount != 4 {
+			t.Errorf("min seeds %d: got torrents count %d, want 4", minSeeds, page.TorrentsCount)
+		}
+		if q := api.requests()[len(api.requests())-1]; q.Has("min_seeds") || q.Has("MinSeeds") {
+			t.Errorf("min seeds %d: sent %v to the API", minSeeds, q)
+		}
+	}
+}
+
+func TestInvalidImdbID(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api)
+
+	tests := []struct {
+		imdbID  string
+		wantErr error
+	}{
+		{"tt1234567", nil},
+		{"1234567", nil},
+		{"tt", ErrMissingImdbID},
+		{"tt12a34", ErrInvalidImdbID},
+		{"Breaking Bad", ErrInvalidImdbID},
+		{"tt-1", ErrInvalidImdbID},
+		{"１２３", ErrInvalidImdbID},
+	}
+	for _, tt := range tests {
+		before := len(api.requests())
+		_, err := c.GetTorrents(context.Background(), URLOptions{ImdbID: tt.imdbID, Page: 1})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q: got %v, want %v", tt.imdbID, err, tt.wantErr)
+		}
+		if sent := len(api.requests()) > before; sent != (tt.wantErr == nil) {
+			t.Errorf("%q: sent a request %t, want %t", tt.imdbID, sent, tt.wantErr == nil)
+		}
+		if tt.wantErr == nil && api.requests()[before].Get("imdb_id") != "1234567" {
+			t.Errorf("%q: requested %v, want imdb_id 1234567", tt.imdbID, api.requests()[before])
+		}
+	}
+
+	// Streams push the same errors, also ErrMissingImdbID for an empty ImdbID, and close.
+	for imdbID, want := range map[string]error{"": ErrMissingImdbID, "tt": ErrMissingImdbID, "tt12a34": ErrInvalidImdbID, "Breaking Bad": ErrInvalidImdbID} {
+		stream := c.TorrentStream(context.Background(), StreamOptions{ImdbID: imdbID})
+		if s := receive(t, stream); !errors.Is(s.Err, want) {
+			t.Errorf("stream %q: got %+v, want %v", imdbID, s, want)
+		}
+		closed(t, stream)
+	}
+}
+
+func TestGetTorrentsAPIError(t *testing.T) {
+	page := strings.Repeat("<html>service unavailable</html>", 100)
+	unavailable := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, page)
+	})
+	c := testClient(t, unavailable)
+
+	_, err := c.GetTorrents(context.Background(), URLOptions{Page: 1})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an *APIError instead of a decoding error", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Status != "503 Service Unavailable" || apiErr.Body != page[:apiErrorBodyLimit] {
+		t.Fatalf("got %d %q with body %q, want the 503 and the first %d bytes of the page", apiErr.StatusCode, apiErr.Status, apiErr.Body, apiErrorBodyLimit)
+	}
+	if want := "eztv api responded with 503 Service Unavailable: <html>"; !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("got %q, want it prefixed with %q", err, want)
+	}
+
+	// Streams push the error like any other.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", RecheckInterval: time.Hour})
+	if s := receive(t, stream); !errors.As(s.Err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got %+v, want the *APIError on the stream", s)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	api := newFakeAPI(150)
+	var mu sync.Mutex
+	var agents []string
+	recording := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, r.UserAgent())
+		mu.Unlock()
+		api.ServeHTTP(w, r)
+	})
+
+	if _, err := testClient(t, recording).GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	c := testClient(t, recording, WithUserAgent("my-app/1.0"))
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	receiveIDs(t, c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", RecheckInterval: time.Hour}), 150)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.HasPrefix(agents[0], "Go-http-client/") {
+		t.Fatalf("got user agent %q without WithUserAgent, want the default one", agents[0])
+	}
+	// The request, and the probe and 2 pages of the re-sync.
+	if len(agents) != 5 {
+		t.Fatalf("made %d requests, want 5", len(agents))
+	}
+	for _, agent := range agents[1:] {
+		if agent != "my-app/1.0" {
+			t.Fatalf("got user agents %q, want my-app/1.0 after the first", agents)
+		}
+	}
+}
+
+func TestGetAllTorrents(t *testing.T) {
+	api := newFakeAPI(250)
+	var served atomic.Int32
+	// A torrent is published after the first page was served, which shifts the later pages.
+	shifting := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.ServeHTTP(w, r)
+		if served.Add(1) == 1 {
+			api.add(torrentsWithIDs(251, 251)...)
+		}
+	})
+
+	torrents, err := testClient(t, shifting).GetAllTorrents(context.Background(), "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(torrents); !slices.Equal(got, ids(torrentsWithIDs(1, 250))) {
+		t.Fatalf("got %d torrents, want each of 250 to 1 once", len(got))
+	}
+	if n := served.Load(); n != 3 {
+		t.Fatalf("made %d requests, want 3", n)
+	}
+
+	// Pages are counted with the limit the API answered with, not the requested one.
+	capped := &fakeAPI{torrents: torrentsWithIDs(1, 250), maxLimit: 50}
+	torrents, err = testClient(t, capped).GetAllTorrents(context.Background(), "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(torrents); !slices.Equal(got, ids(torrentsWithIDs(1, 250))) {
+		t.Fatalf("got %d torrents from pages of 50, want all 250", len(got))
+	}
+	if n := len(capped.requests()); n != 5 {
+		t.Fatalf("made %d requests for pages of 50, want 5", n)
+	}
+
+	torrents, err = testClient(t, &fakeAPI{maxLimit: MaxEZTVAPILimit}).GetAllTorrents(context.Background(), "tt1")
+	if err != nil || torrents == nil || len(torrents) != 0 {
+		t.Fatalf("got %v, %v for a show without torrents, want an empty slice", torrents, err)
+	}
+
+	failPage2 := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	torrents, err = testClient(t, failPage2).GetAllTorrents(context.Background(), "tt1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "get page 2") || torrents != nil {
+		t.Fatalf("got %d torrents, %v, want no torrents and the error of page 2", len(torrents), err)
+	}
+
+	if _, err := testClient(t, api).GetAllTorrents(context.Background(), "tt"); !errors.Is(err, ErrMissingImdbID) {
+		t.Fatalf("got %v, want ErrMissingImdbID", err)
+	}
+}
+
+func TestAllTorrents(t *testing.T) {
+	api := newFakeAPI(250)
+	c := testClient(t, api)
+
+	var got []int
+	for torrent, err := range c.AllTorrents(context.Background(), "tt1") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, torrent.ID)
+	}
+	if !slices.Equal(got, ids(torrentsWithIDs(1, 250))) {
+		t.Fatalf("got %d torrents, want 250 to 1", len(got))
+	}
+
+	// Pages are walked with the limit the API answered with, not the requested one.
+	got = nil
+	capped := &fakeAPI{torrents: torrentsWithIDs(1, 250), maxLimit: 50}
+	for torrent, err := range testClient(t, capped).AllTorrents(context.Background(), "tt1") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, torrent.ID)
+	}
+	if !slices.Equal(got, ids(torrentsWithIDs(1, 250))) {
+		t.Fatalf("got %d torrents from pages of 50, want 250 to 1", len(got))
+	}
+
+	// Breaking out early does not fetch the rest of the pages.
+	before := len(api.requests())
+	for torrent, err := range c.AllTorrents(context.Background(), "tt1") {
+		if err != nil || torrent.ID == 200 {
+			break
+		}
+	}
+	if n := len(api.requests()) - before; n != 1 {
+		t.Fatalf("made %d requests before breaking out on the first page, want 1", n)
+	}
+
+	// The consumer decides whether to carry on after an error.
+	var failures atomic.Int32
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" && failures.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	got, errs := nil, 0
+	for torrent, err := range testClient(t, failing).AllTorrents(context.Background(), "tt1") {
+		if err != nil {
+			errs++
+			continue
+		}
+		got = append(got, torrent.ID)
+	}
+	if errs != 1 || !slices.Equal(got, ids(torrentsWithIDs(1, 250))) {
+		t.Fatalf("got %d errors and %d torrents, want 1 error and 250 to 1 after carrying on", errs, len(got))
+	}
+
+	// Cancelling the context ends the iteration.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs = 0
+	for _, err := range c.AllTorrents(ctx, "tt1") {
+		if err == nil {
+			t.Fatal("got a torrent after the context was cancelled")
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Fatalf("got %d errors after the context was cancelled, want 1", errs)
+	}
+}
+
+func TestWithRequestAndResponseHooks(t *testing.T) {
+	api := newFakeAPI(3)
+	var served atomic.Int32
+	flaky := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if served.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+
+	var requests []string
+	var statuses []int
+	c := testClient(t, flaky,
+		WithRetry(2, time.Millisecond),
+		WithRequestSigner(func(req *http.Request) error {
+			req.Header.Set("X-Signature", "signed")
+			return nil
+		}),
+		WithRequestHook(func(req *http.Request) {
+			requests = append(requests, req.URL.Path+"?"+req.URL.RawQuery+" "+req.Header.Get("X-Signature"))
+		}),
+		WithResponseHook(func(resp *http.Response, err error, elapsed time.Duration) {
+			if err != nil || elapsed <= 0 {
+				t.Errorf("got %v after %s, want a response", err, elapsed)
+			}
+			statuses = append(statuses, resp.StatusCode)
+		}),
+	)
+	// Hooks see every attempt, and the body is still decoded after the response hook.
+	page, err := c.GetTorrents(context.Background(), URLOptions{Page: 1, Limit: 2})
+	if err != nil || len(page.Torrents) != 2 {
+		t.Fatalf("got %v, %v, want 2 torrents", page, err)
+	}
+	if want := []string{"/get-torrents?limit=2&page=1 signed", "/get-torrents?limit=2&page=1 signed"}; !slices.Equal(requests, want) {
+		t.Fatalf("request hook got %q, want %q", requests, want)
+	}
+	if want := []int{http.StatusServiceUnavailable, http.StatusOK}; !slices.Equal(statuses, want) {
+		t.Fatalf("response hook got %v, want %v", statuses, want)
+	}
+
+	// Requests that get no response pass their error to the response hook.
+	srv := httptest.NewServer(api)
+	srv.Close()
+	var hookErr error
+	c = New(WithBaseURL(srv.URL), WithResponseHook(func(resp *http.Response, err error, elapsed time.Duration) {
+		if resp != nil {
+			t.Errorf("got a response %v to a closed server", resp.Status)
+		}
+		hookErr = err
+	}))
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err == nil || hookErr == nil {
+		t.Fatalf("got %v, with %v passed to the hook, want the connection error", err, hookErr)
+	}
+}
@@ -0,0 +1,67 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// BatchChunkSize is the number of shows GetTorrentsBatch fetches concurrently.
+const BatchChunkSize = 10
+
+// ChunkIMDbIDs splits ids into consecutive chunks of at most size IDs.
+// A non-positive size returns all ids in a single chunk.
+func ChunkIMDbIDs(ids []string, size int) [][]string {
+	if len(ids) == 0 {
+		return nil
+	}
+	if size <= 0 {
+		size = len(ids)
+	}
+
+	chunks := make([][]string, 0, (len(ids)+size-1)/size)
+	for size < len(ids) {
+		ids, chunks = ids[size:], append(chunks, ids[:size:size])
+	}
+	return append(chunks, ids)
+}
+
+// GetTorrentsBatch fetches a Page of torrents for each of the given IMDb IDs, using urlOptions
+// for everything but the ImdbID. The result is keyed by the IDs as they were given.
+//
+// Shows are processed in chunks of BatchChunkSize: the shows of a chunk are fetched concurrently
+// and chunks are fetched one after another. Failed shows are left out of the result and
+// their errors are joined into the returned error.
+func (c *Client) GetTorrentsBatch(ctx context.Context, imdbIDs []string, urlOptions URLOptions) (map[string]*Page, error) {
+	var (
+		mu    sync.Mutex
+		pages = make(map[string]*Page, len(imdbIDs))
+		errs  []error
+	)
+
+	for _, chunk := range ChunkIMDbIDs(imdbIDs, BatchChunkSize) {
+		var wg sync.WaitGroup
+		for _, imdbID := range chunk {
+			wg.Add(1)
+			go func(imdbID string) {
+				defer wg.Done()
+
+				opts := urlOptions
+				opts.ImdbID = imdbID
+				page, err := c.GetTorrents(ctx, opts)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("imdb id %s: %w", imdbID, err))
+					return
+				}
+				pages[imdbID] = page
+			}(imdbID)
+		}
+		wg.Wait()
+	}
+
+	return pages, errors.Join(errs...)
+}
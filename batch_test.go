@@ -0,0 +1,37 @@
+package eztv
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChunkIMDbIDs(t *testing.T) {
+	ids := []string{"tt1", "tt2", "tt3", "tt4", "tt5", "tt6", "tt7"}
+	tests := []struct {
+		size int
+		want [][]string
+	}{
+		{3, [][]string{{"tt1", "tt2", "tt3"}, {"tt4", "tt5", "tt6"}, {"tt7"}}},
+		{2, [][]string{{"tt1", "tt2"}, {"tt3", "tt4"}, {"tt5", "tt6"}, {"tt7"}}},
+		{7, [][]string{ids}},
+		{10, [][]string{ids}},
+		{0, [][]string{ids}},
+	}
+	for _, tt := range tests {
+		got := ChunkIMDbIDs(ids, tt.size)
+		if !slices.EqualFunc(got, tt.want, slices.Equal) {
+			t.Errorf("size %d: got %v, want %v", tt.size, got, tt.want)
+		}
+	}
+
+	if got := ChunkIMDbIDs(nil, 3); got != nil {
+		t.Errorf("got %v for no ids, want nil", got)
+	}
+
+	// Appending to a chunk must not overwrite the next one.
+	chunks := ChunkIMDbIDs(ids, 3)
+	_ = append(chunks[0], "tt8")
+	if !slices.Equal(chunks[1], []string{"tt4", "tt5", "tt6"}) {
+		t.Errorf("appending to the first chunk changed the second one to %v", chunks[1])
+	}
+}
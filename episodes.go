@@ -0,0 +1,149 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// episodeRangeRe matches multi-episode markers such as "E01E02", "E01-E03" or "E01-03".
+var episodeRangeRe = regexp.MustCompile(`(?i)E(\d{1,3})(?:-?E|-)(\d{1,3})\b`)
+
+// SeasonPackPatterns are the patterns matched by IsSeasonPack against release names.
+// It can be extended with patterns of other naming schemes.
+var SeasonPackPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bcomplete\b`),
+	regexp.MustCompile(`(?i)\bseasons?[\s._-]*\d{1,3}\b`),
+	regexp.MustCompile(`(?i)\bS\d{1,3}\b`), // e.g. "Show.S02.1080p", but not "Show.S02E01".
+}
+
+// IsSeasonPack reports whether the torrent is a pack of a whole season (or more), judging by
+// its Episode being absent or zero and its release name, parsed from the fields selected by
+// SetParseSource, matching any of SeasonPackPatterns. Multi-episode torrents are not season packs.
+func (t Torrent) IsSeasonPack() bool {
+	if episode, ok := t.EpisodeNumber(); ok && episode != 0 {
+		return false
+	}
+	if _, _, ok := t.EpisodeRange(); ok {
+		return false
+	}
+
+	_, ok := parseTorrent(t, func(s string) (struct{}, bool) {
+		for _, re := range SeasonPackPatterns {
+			if re.MatchString(s) {
+				return struct{}{}, true
+			}
+		}
+		return struct{}{}, false
+	}, func(a, b struct{}) bool { return false })
+	return ok
+}
+
+// EpisodeRange returns the first and last episode numbers of a multi-episode torrent
+// (e.g. "S01E01E02" or "S01E01-E03") parsed from the fields selected by SetParseSource.
+// With ParseBestOfBoth the wider range wins.
+//
+// ok is false when the torrent describes a single episode or no episode at all.
+func (t Torrent) EpisodeRange() (start, end int, ok bool) {
+	r, ok := parseTorrent(t, parseEpisodeRange, func(a, b [2]int) bool {
+		return a[1]-a[0] > b[1]-b[0]
+	})
+	return r[0], r[1], ok
+}
+
+func parseEpisodeRange(s string) ([2]int, bool) {
+	m := episodeRangeRe.FindStringSubmatch(s)
+	if m == nil {
+		return [2]int{}, false
+	}
+
+	start, err := strconv.Atoi(m[1])
+	if err != nil {
+		return [2]int{}, false
+	}
+	end, err := strconv.Atoi(m[2])
+	if err != nil || end <= start {
+		return [2]int{}, false
+	}
+
+	return [2]int{start, end}, true
+}
+
+// FindEpisodes returns the torrents of the show that match each of the requested episodes.
+//
+// The show is paginated only once and every torrent is bucketed into the episodes it covers,
+// including multi-episode torrents (see EpisodeRange). Requested episodes without any torrents
+// map to an empty slice.
+//
+// If the search was cut short by WithMaxSearchPages, the torrents found so far are returned
+// together with ErrSearchTruncated.
+func (c *Client) FindEpisodes(ctx context.Context, imdbID string, episodes []SeasonEpisode) (map[SeasonEpisode][]Torrent, error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return nil, ErrMissingImdbID
+	}
+
+	found := make(map[SeasonEpisode][]Torrent, len(episodes))
+	for _, ep := range episodes {
+		found[ep] = []Torrent{}
+	}
+
+	err := c.forEachPage(ctx, imdbID, c.maxSearchPages, func(page *Page) bool {
+		for _, torrent := range page.Torrents {
+			for _, ep := range torrent.episodes() {
+				if _, ok := found[ep]; ok {
+					found[ep] = append(found[ep], torrent)
+				}
+			}
+		}
+		return true
+	})
+	if err != nil && !errors.Is(err, ErrSearchTruncated) {
+		return nil, err
+	}
+
+	return found, err
+}
+
+// episodes returns every episode covered by the torrent.
+func (t Torrent) episodes() []SeasonEpisode {
+	season, ok := t.SeasonNumber()
+	if !ok {
+		return nil
+	}
+
+	if start, end, ok := t.EpisodeRange(); ok {
+		eps := make([]SeasonEpisode, 0, end-start+1)
+		for ep := start; ep <= end; ep++ {
+			eps = append(eps, SeasonEpisode{Season: season, Episode: ep})
+		}
+		return eps
+	}
+
+	episode, ok := t.EpisodeNumber()
+	if !ok {
+		return nil
+	}
+	return []SeasonEpisode{{Season: season, Episode: episode}}
+}
+
+// FindDuplicateEpisodes returns the episodes that are covered by more than one torrent,
+// together with those torrents. Torrents without season or episode data are ignored.
+func FindDuplicateEpisodes(torrents []Torrent) map[SeasonEpisode][]Torrent {
+	byEpisode := make(map[SeasonEpisode][]Torrent)
+	for _, torrent := range torrents {
+		for _, ep := range torrent.episodes() {
+			byEpisode[ep] = append(byEpisode[ep], torrent)
+		}
+	}
+
+	for ep, ts := range byEpisode {
+		if len(ts) < 2 {
+			delete(byEpisode, ep)
+		}
+	}
+
+	return byEpisode
+}
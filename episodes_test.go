@@ -0,0 +1,110 @@
+package eztv
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestEpisodeRange(t *testing.T) {
+	tests := []struct {
+		title      string
+		start, end int
+		ok         bool
+	}{
+		{"Show.S01E01.1080p.WEB.h264-GROUP", 0, 0, false},
+		{"Show.S01E01E02.1080p.WEB.h264-GROUP", 1, 2, true},
+		{"Show S01E01-E03 720p HDTV x264", 1, 3, true},
+		{"Show.S02E09-10.720p.HDTV.x264", 9, 10, true},
+		{"Show.S02E10-E09.720p", 0, 0, false},
+		{"Show.S02.1080p.WEB", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, end, ok := Torrent{Title: tt.title}.EpisodeRange()
+		if start != tt.start || end != tt.end || ok != tt.ok {
+			t.Errorf("%q: got %d, %d, %t, want %d, %d, %t", tt.title, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+}
+
+func TestFindEpisodes(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit, torrents: []Torrent{
+		{ID: 4, Title: "Show.S01E03.720p", Season: "1", Episode: "3"},
+		{ID: 3, Title: "Show.S01E01E02.1080p", Season: "1", Episode: "1"},
+		{ID: 2, Title: "Show.S01E02.720p", Season: "1", Episode: "2"},
+		{ID: 1, Title: "Show.S01.1080p", Season: "1"},
+	}}
+	c := testClient(t, api)
+
+	s01e02, s01e05, s02e01 := SeasonEpisode{1, 2}, SeasonEpisode{1, 5}, SeasonEpisode{2, 1}
+	found, err := c.FindEpisodes(context.Background(), "tt1", []SeasonEpisode{s01e02, s01e05, s02e01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[SeasonEpisode][]int{s01e02: {3, 2}, s01e05: {}, s02e01: {}}
+	got := make(map[SeasonEpisode][]int, len(found))
+	for ep, torrents := range found {
+		if torrents == nil {
+			t.Errorf("%+v: got a nil slice, want an empty one", ep)
+		}
+		got[ep] = ids(torrents)
+	}
+	if !maps.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if n := len(api.requests()); n != 1 {
+		t.Fatalf("made %d requests, want 1", n)
+	}
+}
+
+func TestFindDuplicateEpisodes(t *testing.T) {
+	torrents := []Torrent{
+		{ID: 1, Title: "Show.S01E01.720p", Season: "1", Episode: "1"},
+		{ID: 2, Title: "Show.S01E01.1080p", Season: "1", Episode: "1"},
+		{ID: 3, Title: "Show.S01E02E03.1080p", Season: "1", Episode: "2"},
+		{ID: 4, Title: "Show.S01E03.720p", Season: "1", Episode: "3"},
+		{ID: 5, Title: "Show.S01E04.720p", Season: "1", Episode: "4"},
+		{ID: 6, Title: "Show.S01.1080p", Season: "1"},
+		{ID: 7, Title: "Show.S01.720p", Season: "1"},
+		{ID: 8, Title: "Show.Special.720p"},
+	}
+	got := make(map[SeasonEpisode][]int)
+	for ep, ts := range FindDuplicateEpisodes(torrents) {
+		got[ep] = ids(ts)
+	}
+	want := map[SeasonEpisode][]int{{1, 1}: {1, 2}, {1, 3}: {3, 4}}
+	if !maps.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if dups := FindDuplicateEpisodes(torrents[4:]); len(dups) != 0 {
+		t.Fatalf("got duplicates %v among unique episodes", dups)
+	}
+}
+
+func TestIsSeasonPack(t *testing.T) {
+	tests := []struct {
+		torrent Torrent
+		want    bool
+	}{
+		{Torrent{Title: "The Bear S02 COMPLETE 1080p WEB H264-SuccessfulCrab[TGx] EZTV", Season: "2", Episode: "0"}, true},
+		{Torrent{Title: "Severance.S01.1080p.ATVP.WEB-DL.DDP5.1.H.264-NTb", Season: "1"}, true},
+		{Torrent{Title: "Succession Season 4 Complete 720p AMZN WEBRip x264 [i_c]", Season: "4"}, true},
+		{Torrent{Title: "Doctor Who Seasons 1-13 Complete 720p"}, true},
+		{Torrent{Title: "The.Office.US.COMPLETE.SERIES.720p.BluRay.x264"}, true},
+		{Torrent{Title: "The Bear S02E01 1080p WEB H264-SuccessfulCrab EZTV", Season: "2", Episode: "1"}, false},
+		{Torrent{Title: "Severance.S01E09.The.We.We.Are.1080p.ATVP.WEB-DL.DDP5.1.H.264-NTb", Season: "1", Episode: "9"}, false},
+		{Torrent{Title: "Below.Deck.S11E01E02.720p.WEB.h264-EDITH", Season: "11", Episode: "0"}, false},
+		{Torrent{Title: "Jeopardy 2024 01 15 720p HDTV x264-NTb"}, false},
+		{Torrent{Title: "Doctor Who 2023 The Giggle 1080p DSNP WEB-DL"}, false},
+		// The episode field wins over a misleading release name.
+		{Torrent{Title: "Show.Complete.Guide.S01E05.720p", Season: "1", Episode: "5"}, false},
+		{Torrent{Title: "Show.S03.E04.720p", Season: "3", Episode: "4"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.torrent.IsSeasonPack(); got != tt.want {
+			t.Errorf("%q: got %t, want %t", tt.torrent.Title, got, tt.want)
+		}
+	}
+}
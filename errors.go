@@ -0,0 +1,49 @@
+package eztv
+
+import "fmt"
+
+// InconsistentPageError is returned when the API reports torrents for a page
+// that should contain some, but the page itself is empty.
+type InconsistentPageError struct {
+	Page          int
+	Limit         int
+	TorrentsCount int
+}
+
+func (e *InconsistentPageError) Error() string {
+	return fmt.Sprintf("inconsistent page %d: torrents_count is %d with limit %d, but no torrents were returned", e.Page, e.TorrentsCount, e.Limit)
+}
+
+// apiErrorBodyLimit is the maximum number of bytes of the response body kept in APIError.
+const apiErrorBodyLimit = 512
+
+// APIError is returned when the API responds with a non-successful (4xx or 5xx) status code.
+// It allows to tell apart e.g. rate-limiting (429) from outages (5xx).
+type APIError struct {
+	StatusCode int
+	Status     string
+	// Body is the beginning of the response body, truncated to 512 bytes.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("eztv api responded with %s", e.Status)
+	}
+	return fmt.Sprintf("eztv api responded with %s: %s", e.Status, e.Body)
+}
+
+// RetryExhaustedError is returned when a request failed on every attempt allowed by WithRetry.
+// It unwraps to the error of the last attempt.
+type RetryExhaustedError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryExhaustedError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.Err
+}
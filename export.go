@@ -0,0 +1,134 @@
+package eztv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportManifestFile is the name of the file in which ExportToDir records its progress.
+const ExportManifestFile = "manifest.json"
+
+// exportManifest is the progress of an export as it is stored on disk.
+type exportManifest struct {
+	ImdbID        string `json:"imdb_id"`
+	TorrentsCount int    `json:"torrents_count"`
+	Limit         int    `json:"limit"`
+	LastPage      int    `json:"last_page"`
+}
+
+// ExportToDir writes every page of MaxEZTVAPILimit torrents of the show into dir, as the JSON
+// encoded Page in a file named after its number (e.g. "page-0001.json"). The progress is recorded
+// in ExportManifestFile after every page, so that running it again for the same show and dir
+// resumes after the last written page.
+//
+// Since adding torrents shifts the torrents of every page, the export starts over
+// if the number of torrents of the show changed since the manifest was written.
+// It also starts over if the API answers with a different limit than the one the
+// written pages have, as their torrents would no longer line up with the next page.
+func (c *Client) ExportToDir(ctx context.Context, imdbID, dir string) error {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return ErrMissingImdbID
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	manifest, err := readExportManifest(dir)
+	if err != nil {
+		return err
+	}
+	if manifest.ImdbID != imdbID {
+		if err := resetExport(dir, &manifest, imdbID); err != nil {
+			return err
+		}
+	}
+
+	for i := manifest.LastPage + 1; ; i++ {
+		page, err := c.GetTorrents(ctx, URLOptions{
+			ImdbID: imdbID,
+			Page:   i,
+			Limit:  MaxEZTVAPILimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		limit := page.effectiveLimit(MaxEZTVAPILimit)
+		if manifest.LastPage > 0 && (page.TorrentsCount != manifest.TorrentsCount || limit != manifest.Limit) {
+			if err := resetExport(dir, &manifest, imdbID); err != nil {
+				return err
+			}
+			i = 0
+			continue
+		}
+		manifest.TorrentsCount = page.TorrentsCount
+		manifest.Limit = limit
+
+		if len(page.Torrents) > 0 {
+			if err := writeJSONFile(filepath.Join(dir, fmt.Sprintf("page-%04d.json", i)), page); err != nil {
+				return err
+			}
+			manifest.LastPage = i
+		}
+		if err := writeJSONFile(filepath.Join(dir, ExportManifestFile), manifest); err != nil {
+			return err
+		}
+
+		if len(page.Torrents) == 0 || i >= page.lastPage(MaxEZTVAPILimit) {
+			return nil
+		}
+	}
+}
+
+// readExportManifest reads the manifest of the export into dir, if there is any.
+func readExportManifest(dir string) (exportManifest, error) {
+	var manifest exportManifest
+	b, err := os.ReadFile(filepath.Join(dir, ExportManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return manifest, fmt.Errorf("decode export manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// resetExport removes the pages written into dir so far and resets the manifest to start the export over.
+func resetExport(dir string, manifest *exportManifest, imdbID string) error {
+	pages, err := filepath.Glob(filepath.Join(dir, "page-*.json"))
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		if err := os.Remove(page); err != nil {
+			return err
+		}
+	}
+
+	*manifest = exportManifest{ImdbID: imdbID}
+	return nil
+}
+
+// writeJSONFile atomically replaces the file at path with v encoded as JSON.
+func writeJSONFile(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
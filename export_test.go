@@ -0,0 +1,138 @@
+package eztv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// exportedIDs returns the IDs of the torrents in the pages exported into dir, in page order.
+func exportedIDs(t *testing.T, dir string) []int {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "page-*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var page Page
+		if err := json.Unmarshal(b, &page); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ids(page.Torrents)...)
+	}
+	return got
+}
+
+func TestExportToDirResume(t *testing.T) {
+	api := newFakeAPI(250)
+	failPage3 := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "3" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	dir := t.TempDir()
+
+	if err := testClient(t, failPage3).ExportToDir(context.Background(), "tt1", dir); err == nil {
+		t.Fatal("export succeeded, although page 3 failed")
+	}
+	manifest, err := readExportManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest != (exportManifest{ImdbID: "1", TorrentsCount: 250, Limit: MaxEZTVAPILimit, LastPage: 2}) {
+		t.Fatalf("got manifest %+v after the failure", manifest)
+	}
+
+	before := len(api.requests())
+	if err := testClient(t, api).ExportToDir(context.Background(), "tt1", dir); err != nil {
+		t.Fatal(err)
+	}
+	var pages []string
+	for _, q := range api.requests()[before:] {
+		pages = append(pages, q.Get("page"))
+	}
+	if !slices.Equal(pages, []string{"3"}) {
+		t.Fatalf("resumed export requested pages %v, want only [3]", pages)
+	}
+	if got := exportedIDs(t, dir); !slices.Equal(got, ids(torrentsWithIDs(1, 250))) {
+		t.Fatalf("exported %d torrents, want 250 to 1", len(got))
+	}
+}
+
+func TestExportToDirStartsOver(t *testing.T) {
+	api := newFakeAPI(150)
+	dir := t.TempDir()
+	c := testClient(t, api)
+	if err := c.ExportToDir(context.Background(), "tt1", dir); err != nil {
+		t.Fatal(err)
+	}
+	api.add(torrentsWithIDs(151, 151)...)
+
+	// The manifest is complete, but the torrents of every page shifted by the new one.
+	if err := writeJSONFile(filepath.Join(dir, ExportManifestFile), exportManifest{ImdbID: "1", TorrentsCount: 150, Limit: MaxEZTVAPILimit, LastPage: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ExportToDir(context.Background(), "tt1", dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := exportedIDs(t, dir); !slices.Equal(got, ids(torrentsWithIDs(1, 151))) {
+		t.Fatalf("exported %v, want 151 to 1", got)
+	}
+
+	// Exporting another show into the same dir replaces the pages of the previous one.
+	if err := c.ExportToDir(context.Background(), "tt2", dir); err != nil {
+		t.Fatal(err)
+	}
+	if manifest, err := readExportManifest(dir); err != nil || manifest.ImdbID != "2" {
+		t.Fatalf("got manifest %+v, %v, want the one of tt2", manifest, err)
+	}
+}
+
+func TestExportToDirAnsweredLimit(t *testing.T) {
+	api := newFakeAPI(250)
+	failPage3 := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "3" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	dir := t.TempDir()
+	if err := testClient(t, failPage3).ExportToDir(context.Background(), "tt1", dir); err == nil {
+		t.Fatal("export succeeded, although page 3 failed")
+	}
+
+	// The API now answers with pages of 50, so the pages of 100 written so far no longer line up.
+	api.maxLimit = 50
+	if err := testClient(t, api).ExportToDir(context.Background(), "tt1", dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := exportedIDs(t, dir); !slices.Equal(got, ids(torrentsWithIDs(1, 250))) {
+		t.Fatalf("exported %d torrents, want 250 to 1", len(got))
+	}
+	manifest, err := readExportManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest != (exportManifest{ImdbID: "1", TorrentsCount: 250, Limit: 50, LastPage: 5}) {
+		t.Fatalf("got manifest %+v, want the 5 pages of 50", manifest)
+	}
+}
+
+func TestExportToDirMissingImdbID(t *testing.T) {
+	if err := testClient(t, newFakeAPI(1)).ExportToDir(context.Background(), "tt", t.TempDir()); !errors.Is(err, ErrMissingImdbID) {
+		t.Fatalf("got %v, want ErrMissingImdbID", err)
+	}
+}
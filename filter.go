@@ -0,0 +1,311 @@
+package eztv
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// numericFilterFields are the fields a filter compares as numbers.
+var numericFilterFields = map[string]func(t Torrent) (int64, bool){
+	"id":    func(t Torrent) (int64, bool) { return int64(t.ID), true },
+	"seeds": func(t Torrent) (int64, bool) { return int64(t.Seeds), true },
+	"peers": func(t Torrent) (int64, bool) { return int64(t.Peers), true },
+	"season": func(t Torrent) (int64, bool) {
+		season, ok := t.SeasonNumber()
+		return int64(season), ok
+	},
+	"episode": func(t Torrent) (int64, bool) {
+		episode, ok := t.EpisodeNumber()
+		return int64(episode), ok
+	},
+	"size": func(t Torrent) (int64, bool) {
+		size, err := t.Size()
+		return size, err == nil
+	},
+}
+
+// stringFilterFields are the fields a filter compares as case-insensitive strings.
+var stringFilterFields = map[string]func(t Torrent) string{
+	"quality": func(t Torrent) string { return t.Resolution().String() },
+	"source":  func(t Torrent) string { return t.Source().String() },
+	"group":   func(t Torrent) string { return t.ReleaseGroup() },
+	"title":   func(t Torrent) string { return t.Title },
+}
+
+// stringFilterNormalizers normalize both sides of string comparisons of the fields that are not
+// just compared case-insensitively.
+var stringFilterNormalizers = map[string]func(s string) string{
+	"title": NormalizeReleaseName,
+}
+
+var (
+	properRe = regexp.MustCompile(`(?i)\bproper\b`)
+	repackRe = regexp.MustCompile(`(?i)\brepack\b`)
+)
+
+// filterFlags are the bare words a filter matches on their own.
+var filterFlags = map[string]func(t Torrent) bool{
+	"proper": func(t Torrent) bool { return matchRelease(t, properRe) },
+	"repack": func(t Torrent) bool { return matchRelease(t, repackRe) },
+	"pack":   Torrent.IsSeasonPack,
+}
+
+// matchRelease reports whether re matches the fields selected by SetParseSource.
+func matchRelease(t Torrent, re *regexp.Regexp) bool {
+	_, ok := parseTorrent(t, func(s string) (struct{}, bool) {
+		return struct{}{}, re.MatchString(s)
+	}, func(a, b struct{}) bool { return false })
+	return ok
+}
+
+// ParseFilter parses a filter expression into a predicate over torrents, e.g.
+//
+//	seeds>50 and quality=1080p and not proper
+//	(source=web-dl or source=bluray) and title~"director's cut"
+//
+// An expression combines terms with "and", "or" and "not", in order of increasing precedence,
+// and parentheses. A term is either a comparison of a field with a value or a flag:
+//   - id, seeds, peers, season, episode and size (in bytes) are compared as numbers
+//     with =, !=, <, <=, > or >=;
+//   - quality (the Resolution, e.g. 1080p), source (the Source, e.g. WEB-DL), group (the ReleaseGroup)
+//     and title are compared case-insensitively with = or !=, or with ~ for containing the value,
+//     with titles compared after NormalizeReleaseName, so scene and P2P names match either style;
+//   - the flags proper and repack match releases marked as such, and pack matches season packs.
+//
+// Values containing spaces or operator characters are double-quoted. Fields whose value cannot be
+// parsed from the torrent, such as the season of a torrent without one, never match a comparison.
+// Keywords and field names are case-insensitive. Syntax errors wrap ErrInvalidFilter.
+func ParseFilter(expr string) (func(Torrent) bool, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != filterEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return match, nil
+}
+
+type filterTokenKind int
+
+const (
+	filterEOF filterTokenKind = iota
+	filterWord
+	filterString
+	filterOp
+	filterLParen
+	filterRParen
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+// tokenizeFilter splits the filter expression into its tokens, ending with a filterEOF token.
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: filterLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: filterRParen, text: ")", pos: i})
+			i++
+		case c == '"':
+			end := strings.IndexByte(expr[i+1:], '"')
+			if end == -1 {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidFilter, i)
+			}
+			tokens = append(tokens, filterToken{kind: filterString, text: expr[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case strings.IndexByte("=!<>~", c) != -1:
+			op := expr[i : i+1]
+			if i+1 < len(expr) && expr[i+1] == '=' && c != '=' && c != '~' {
+				op = expr[i : i+2]
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("%w: unexpected \"!\" at position %d", ErrInvalidFilter, i)
+			}
+			tokens = append(tokens, filterToken{kind: filterOp, text: op, pos: i})
+			i += len(op)
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t\n()\"=!<>~", rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterWord, text: expr[start:i], pos: start})
+		}
+	}
+
+	return append(tokens, filterToken{kind: filterEOF, text: "end of filter", pos: len(expr)}), nil
+}
+
+// filterParser is a recursive descent parser of filter expressions.
+type filterParser struct {
+	tokens []filterToken
+	i      int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.i]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.i]
+	if tok.kind != filterEOF {
+		p.i++
+	}
+	return tok
+}
+
+// keyword consumes the next token if it is the given keyword.
+func (p *filterParser) keyword(word string) bool {
+	if tok := p.peek(); tok.kind == filterWord && strings.EqualFold(tok.text, word) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) errorf(tok filterToken, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidFilter, fmt.Sprintf(format, args...), tok.pos)
+}
+
+func (p *filterParser) parseOr() (func(Torrent) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t Torrent) bool { return l(t) || right(t) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (func(Torrent) bool, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t Torrent) bool { return l(t) && right(t) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (func(Torrent) bool, error) {
+	if p.keyword("not") {
+		match, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(t Torrent) bool { return !match(t) }, nil
+	}
+
+	if p.peek().kind == filterLParen {
+		p.next()
+		match, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != filterRParen {
+			return nil, p.errorf(tok, "expected \")\", got %q", tok.text)
+		}
+		return match, nil
+	}
+
+	return p.parseTerm()
+}
+
+func (p *filterParser) parseTerm() (func(Torrent) bool, error) {
+	tok := p.next()
+	if tok.kind != filterWord {
+		return nil, p.errorf(tok, "expected a field or flag, got %q", tok.text)
+	}
+	name := strings.ToLower(tok.text)
+
+	if p.peek().kind != filterOp {
+		flag, ok := filterFlags[name]
+		if !ok {
+			return nil, p.errorf(tok, "unknown flag %q", tok.text)
+		}
+		return flag, nil
+	}
+	op := p.next()
+
+	value := p.next()
+	if value.kind != filterWord && value.kind != filterString {
+		return nil, p.errorf(value, "expected a value, got %q", value.text)
+	}
+
+	if field, ok := numericFilterFields[name]; ok {
+		n, err := strconv.ParseInt(value.text, 10, 64)
+		if err != nil {
+			return nil, p.errorf(value, "%s expects a number, got %q", name, value.text)
+		}
+		compare, ok := numericFilterOps[op.text]
+		if !ok {
+			return nil, p.errorf(op, "%s cannot be compared with %q", name, op.text)
+		}
+		return func(t Torrent) bool {
+			v, ok := field(t)
+			return ok && compare(v, n)
+		}, nil
+	}
+
+	if field, ok := stringFilterFields[name]; ok {
+		normalize, ok := stringFilterNormalizers[name]
+		if !ok {
+			normalize = strings.ToLower
+		}
+		want := normalize(value.text)
+		switch op.text {
+		case "=":
+			return func(t Torrent) bool { return normalize(field(t)) == want }, nil
+		case "!=":
+			return func(t Torrent) bool { return normalize(field(t)) != want }, nil
+		case "~":
+			return func(t Torrent) bool { return strings.Contains(normalize(field(t)), want) }, nil
+		}
+		return nil, p.errorf(op, "%s cannot be compared with %q", name, op.text)
+	}
+
+	return nil, p.errorf(tok, "unknown field %q", tok.text)
+}
+
+// numericFilterOps are the comparison operators of numeric fields.
+var numericFilterOps = map[string]func(a, b int64) bool{
+	"=":  func(a, b int64) bool { return a == b },
+	"!=": func(a, b int64) bool { return a != b },
+	"<":  func(a, b int64) bool { return a < b },
+	"<=": func(a, b int64) bool { return a <= b },
+	">":  func(a, b int64) bool { return a > b },
+	">=": func(a, b int64) bool { return a >= b },
+}
@@ -0,0 +1,106 @@
+package eztv
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	torrents := []Torrent{
+		{ID: 1, Title: "Show.S01E01.1080p.WEB-DL.DDP5.1.H.264-NTb", Season: "1", Episode: "1", Seeds: 120, Peers: 10, SizeBytes: "2000000000"},
+		{ID: 2, Title: "Show.S01E01.PROPER.720p.HDTV.x264-KILLERS", Season: "1", Episode: "1", Seeds: 40, Peers: 3, SizeBytes: "800000000"},
+		{ID: 3, Title: "Show.S01E02.REPACK.1080p.BluRay.x264-ROVERS", Season: "1", Episode: "2", Seeds: 60, SizeBytes: "3000000000"},
+		{ID: 4, Title: "Show.S02.1080p.WEB.h264-NTb", Season: "2", Seeds: 300, SizeBytes: "20000000000"},
+		{ID: 5, Title: "Show.Special.480p.x264", Seeds: 0},
+	}
+
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{"seeds>50", []int{1, 3, 4}},
+		{"seeds>=60 and seeds<=120", []int{1, 3}},
+		{"seeds=0 or peers!=0", []int{1, 2, 5}},
+		{"season=1 and episode=1", []int{1, 2}},
+		{"season<2", []int{1, 2, 3}},
+		{"episode>0", []int{1, 2, 3}},
+		{"size>1000000000", []int{1, 3, 4}},
+		{"quality=1080p", []int{1, 3, 4}},
+		{"QUALITY = 720P", []int{2}},
+		{"source=web-dl", []int{1, 4}},
+		{"source!=web-dl", []int{2, 3, 5}},
+		{"group=ntb", []int{1, 4}},
+		{`title~"bluray"`, []int{3}},
+		{"proper or repack", []int{2, 3}},
+		{"pack", []int{4}},
+		{"not pack and quality=1080p", []int{1, 3}},
+		{"(source=web-dl or source=bluray) and not proper and seeds>50", []int{1, 3, 4}},
+		{"seeds>50 and quality=1080p or proper", []int{1, 2, 3, 4}},
+		{"seeds>50 and (quality=720p or proper)", []int{}},
+		{"not not pack", []int{4}},
+		{"id=3 OR id=5", []int{3, 5}},
+	}
+	for _, tt := range tests {
+		match, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		got := []int{}
+		for _, torrent := range torrents {
+			if match(torrent) {
+				got = append(got, torrent.ID)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: matched %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilterSyntaxError(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "expected a field or flag"},
+		{"seeds>", "expected a value"},
+		{"seeds>many", `seeds expects a number, got "many"`},
+		{"seeds~5", `seeds cannot be compared with "~"`},
+		{"quality>1080p", `quality cannot be compared with ">"`},
+		{"colour=red", `unknown field "colour"`},
+		{"shiny", `unknown flag "shiny"`},
+		{"(pack", "position 5"},
+		{"pack)", `unexpected ")"`},
+		{"pack and", "expected a field or flag"},
+		{`title="unterminated`, "unterminated string at position 6"},
+		{"seeds!5", `unexpected "!" at position 5`},
+	}
+	for _, tt := range tests {
+		match, err := ParseFilter(tt.expr)
+		if !errors.Is(err, ErrInvalidFilter) || !strings.Contains(err.Error(), tt.want) || match != nil {
+			t.Errorf("%q: got %v, want ErrInvalidFilter with %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestParseFilterNormalizesTitles(t *testing.T) {
+	scene := Torrent{Title: "Show.S01E01.1080p.WEB.h264-GROUP"}
+	p2p := Torrent{Title: "Show S01E01 [1080p WEB H.264] GROUP [eztv]"}
+
+	for _, expr := range []string{
+		`title="Show S01E01 1080p WEB H264 GROUP"`,
+		`title="show.s01e01.1080p.web.h.264-group"`,
+		`title~"s01e01 1080p"`,
+		`title~"web.h264"`,
+	} {
+		match, err := ParseFilter(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if !match(scene) || !match(p2p) {
+			t.Errorf("%s: matched scene %t and p2p %t, want both", expr, match(scene), match(p2p))
+		}
+	}
+}
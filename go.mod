@@ -1,3 +1,5 @@
 module github.com/PauliusLozys/eztv
 
-go 1.21.0
+go 1.23.0
+
+require golang.org/x/time v0.10.0
@@ -0,0 +1,47 @@
+package eztv
+
+import (
+	"math"
+	"time"
+)
+
+// UnknownDateBucket is the BucketByDate key of torrents without a release date.
+const UnknownDateBucket = math.MinInt
+
+// BucketByDate groups torrents by how many buckets ago they were released relative to now.
+// Key 0 is the current bucket, 1 the one before it and so on. With a bucket of 24 hours
+// this groups torrents per day.
+//
+// Torrents without a release date are put under the UnknownDateBucket key and torrents
+// released after now get negative keys. A non-positive bucket puts every torrent with
+// a known release date into bucket 0.
+func BucketByDate(torrents []Torrent, bucket time.Duration, now time.Time) map[int][]Torrent {
+	buckets := make(map[int][]Torrent)
+	for _, torrent := range torrents {
+		releasedAt := torrent.ReleasedAt()
+		if releasedAt.IsZero() {
+			buckets[UnknownDateBucket] = append(buckets[UnknownDateBucket], torrent)
+			continue
+		}
+
+		key := 0
+		if bucket > 0 {
+			age := now.Sub(releasedAt)
+			key = int(math.Floor(float64(age) / float64(bucket)))
+		}
+		buckets[key] = append(buckets[key], torrent)
+	}
+
+	return buckets
+}
+
+// AggregateSwarm returns the total number of seeds and peers across the torrents,
+// together with the number of torrents.
+func AggregateSwarm(torrents []Torrent) (totalSeeds, totalPeers, count int) {
+	for _, torrent := range torrents {
+		totalSeeds += torrent.Seeds
+		totalPeers += torrent.Peers
+	}
+
+	return totalSeeds, totalPeers, len(torrents)
+}
@@ -0,0 +1,43 @@
+package eztv
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBucketByDate(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	at := func(id int, releasedAt time.Time) Torrent {
+		return Torrent{ID: id, DateReleasedUnix: int(releasedAt.Unix())}
+	}
+	torrents := []Torrent{
+		at(1, now.Add(-time.Hour)),
+		at(2, now.Add(-23*time.Hour)),
+		at(3, now.Add(-25*time.Hour)),
+		at(4, now.Add(-72*time.Hour)),
+		{ID: 5},
+		at(6, now.Add(2*time.Hour)),
+	}
+
+	got := make(map[int][]int)
+	for key, ts := range BucketByDate(torrents, 24*time.Hour, now) {
+		got[key] = ids(ts)
+	}
+	want := map[int][]int{0: {1, 2}, 1: {3}, 3: {4}, UnknownDateBucket: {5}, -1: {6}}
+	if !maps.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestAggregateSwarm(t *testing.T) {
+	seeds, peers, count := AggregateSwarm([]Torrent{{Seeds: 10, Peers: 2}, {Seeds: 0, Peers: 7}, {Seeds: 5, Peers: 1}})
+	if seeds != 15 || peers != 10 || count != 3 {
+		t.Fatalf("got %d seeds, %d peers, %d torrents, want 15, 10, 3", seeds, peers, count)
+	}
+
+	if seeds, peers, count := AggregateSwarm(nil); seeds != 0 || peers != 0 || count != 0 {
+		t.Fatalf("got %d seeds, %d peers, %d torrents for no torrents, want zeros", seeds, peers, count)
+	}
+}
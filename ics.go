@@ -0,0 +1,59 @@
+package eztv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// TorrentsToICS writes the torrents as an iCalendar (RFC 5545) calendar with one event per torrent,
+// starting at its release date, with the title as summary and the magnet link as description.
+// Torrents with an unknown release date are skipped.
+func TorrentsToICS(torrents []Torrent, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	writeICSLine(bw, "BEGIN:VCALENDAR")
+	writeICSLine(bw, "VERSION:2.0")
+	writeICSLine(bw, "PRODID:-//github.com/PauliusLozys/eztv//EN")
+	for _, torrent := range torrents {
+		releasedAt := torrent.ReleasedAt()
+		if releasedAt.IsZero() {
+			continue
+		}
+
+		writeICSLine(bw, "BEGIN:VEVENT")
+		writeICSLine(bw, fmt.Sprintf("UID:%d@eztv", torrent.ID))
+		writeICSLine(bw, "DTSTAMP:"+releasedAt.Format(icsTimeFormat))
+		writeICSLine(bw, "DTSTART:"+releasedAt.Format(icsTimeFormat))
+		writeICSLine(bw, "SUMMARY:"+icsEscaper.Replace(torrent.Title))
+		writeICSLine(bw, "DESCRIPTION:"+icsEscaper.Replace(torrent.MagnetURL))
+		writeICSLine(bw, "END:VEVENT")
+	}
+	writeICSLine(bw, "END:VCALENDAR")
+
+	return bw.Flush()
+}
+
+// writeICSLine writes a CRLF terminated content line, folding it into lines of at most 75 octets.
+// Errors are left for the final Flush to report.
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) { // Do not split multi-byte characters.
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // Folded lines start with a space.
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
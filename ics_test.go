@@ -0,0 +1,71 @@
+package eztv
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTorrentsToICS(t *testing.T) {
+	magnet := "magnet:?xt=urn:btih:abcdef0123456789abcdef0123456789abcdef01&dn=Show&tr=" + strings.Repeat("udp%3A%2F%2Ftracker", 5)
+	torrents := []Torrent{
+		{ID: 2, Title: "Show S01E02, Part 2; Finale", MagnetURL: magnet, DateReleasedUnix: 1_700_000_000},
+		{ID: 1, Title: "Show S01E01", MagnetURL: "magnet:?xt=urn:btih:01"},
+		{ID: 3, Title: "Show S01E03 – Ünïcödé " + strings.Repeat("é", 40), DateReleasedUnix: 1_700_086_400},
+	}
+
+	var sb strings.Builder
+	if err := TorrentsToICS(torrents, &sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Fatal("calendar does not end with CRLF")
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets is not folded: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("folding split a character in line %q", line)
+		}
+		if strings.Contains(line, "\n") {
+			t.Errorf("line %q has a bare line feed", line)
+		}
+	}
+
+	// Unfold the content lines as RFC 5545 readers do.
+	var unfolded []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+	want := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//github.com/PauliusLozys/eztv//EN",
+		"BEGIN:VEVENT",
+		"UID:2@eztv",
+		"DTSTAMP:20231114T221320Z",
+		"DTSTART:20231114T221320Z",
+		`SUMMARY:Show S01E02\, Part 2\; Finale`,
+		"DESCRIPTION:" + magnet,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:3@eztv",
+		"DTSTAMP:20231115T221320Z",
+		"DTSTART:20231115T221320Z",
+		"SUMMARY:" + torrents[2].Title,
+		"DESCRIPTION:",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	if strings.Join(unfolded, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got calendar\n%s\nwant\n%s", strings.Join(unfolded, "\n"), strings.Join(want, "\n"))
+	}
+}
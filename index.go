@@ -0,0 +1,97 @@
+package eztv
+
+import (
+	"context"
+	"slices"
+)
+
+// Index is an in-memory index of the torrents of a show, built with BuildIndex.
+// It allows to search the torrents without making any further requests.
+//
+// To keep it compact, the screenshots and episode URL of the torrents are not kept.
+type Index struct {
+	torrents     []Torrent
+	byEpisode    map[SeasonEpisode][]int
+	byResolution map[Resolution][]int
+	byHash       map[string]int
+}
+
+// BuildIndex fetches every torrent of the show and indexes them by episode, resolution and info hash.
+// Resolutions are parsed from the fields selected by SetParseSource at the time of the call.
+func (c *Client) BuildIndex(ctx context.Context, imdbID string) (*Index, error) {
+	torrents, err := c.GetAllTorrents(ctx, imdbID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newIndex(torrents), nil
+}
+
+func newIndex(torrents []Torrent) *Index {
+	idx := &Index{
+		torrents:     make([]Torrent, len(torrents)),
+		byEpisode:    make(map[SeasonEpisode][]int),
+		byResolution: make(map[Resolution][]int),
+		byHash:       make(map[string]int, len(torrents)),
+	}
+	for i, torrent := range torrents {
+		torrent.SmallScreenshotURL, torrent.LargeScreenshotURL, torrent.EpisodeURL = "", "", ""
+		idx.torrents[i] = torrent
+
+		for _, ep := range torrent.episodes() {
+			idx.byEpisode[ep] = append(idx.byEpisode[ep], i)
+		}
+		res := torrent.Resolution()
+		idx.byResolution[res] = append(idx.byResolution[res], i)
+		if hash, ok := torrentHash(torrent); ok {
+			if _, ok := idx.byHash[hash]; !ok {
+				idx.byHash[hash] = i
+			}
+		}
+	}
+
+	return idx
+}
+
+// Len returns the number of indexed torrents.
+func (idx *Index) Len() int {
+	return len(idx.torrents)
+}
+
+// Torrents returns all indexed torrents, newest first.
+func (idx *Index) Torrents() []Torrent {
+	return slices.Clone(idx.torrents)
+}
+
+// ByEpisode returns the torrents covering the episode, including multi-episode torrents, newest first.
+func (idx *Index) ByEpisode(season, episode int) []Torrent {
+	return idx.lookup(idx.byEpisode[SeasonEpisode{Season: season, Episode: episode}])
+}
+
+// ByResolution returns the torrents of the resolution, newest first.
+func (idx *Index) ByResolution(res Resolution) []Torrent {
+	return idx.lookup(idx.byResolution[res])
+}
+
+// ByHash returns the torrent with the info hash, given either in hex or base32.
+// If several torrents share the hash, the newest one is returned.
+func (idx *Index) ByHash(hash string) (Torrent, bool) {
+	hash, ok := canonicalHash(hash)
+	if !ok {
+		return Torrent{}, false
+	}
+	i, ok := idx.byHash[hash]
+	if !ok {
+		return Torrent{}, false
+	}
+	return idx.torrents[i], true
+}
+
+// lookup returns the indexed torrents at positions.
+func (idx *Index) lookup(positions []int) []Torrent {
+	torrents := make([]Torrent, len(positions))
+	for i, pos := range positions {
+		torrents[i] = idx.torrents[pos]
+	}
+	return torrents
+}
@@ -0,0 +1,69 @@
+package eztv
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildIndex(t *testing.T) {
+	const hash = "abcdef0123456789abcdef0123456789abcdef01"
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+	api.add(
+		Torrent{ID: 1, Title: "Show.S01E01.720p.HDTV.x264", Season: "1", Episode: "1", Hash: strings.ToUpper(hash)},
+		Torrent{ID: 2, Title: "Show.S01E01.1080p.WEB.h264", Season: "1", Episode: "1", Hash: "1111111111111111111111111111111111111111"},
+		Torrent{ID: 3, Title: "Show.S01E02E03.1080p.WEB.h264", Season: "1", Episode: "2", EpisodeURL: "https://eztv.example/ep/3", SmallScreenshotURL: "small.jpg"},
+		Torrent{ID: 4, Title: "Show.S02E01.720p.WEB.h264", Season: "2", Episode: "1", Hash: hash},
+	)
+	idx, err := testClient(t, api).BuildIndex(context.Background(), "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idx.Len() != 4 || !slices.Equal(ids(idx.Torrents()), []int{4, 3, 2, 1}) {
+		t.Fatalf("indexed %v, want [4 3 2 1]", ids(idx.Torrents()))
+	}
+	for _, torrent := range idx.Torrents() {
+		if torrent.EpisodeURL != "" || torrent.SmallScreenshotURL != "" {
+			t.Fatalf("torrent %d keeps fields that are not needed: %+v", torrent.ID, torrent)
+		}
+	}
+
+	for _, tt := range []struct {
+		season, episode int
+		want            []int
+	}{
+		{1, 1, []int{2, 1}},
+		{1, 2, []int{3}},
+		{1, 3, []int{3}},
+		{2, 1, []int{4}},
+		{3, 1, []int{}},
+	} {
+		if got := ids(idx.ByEpisode(tt.season, tt.episode)); !slices.Equal(got, tt.want) {
+			t.Errorf("S%02dE%02d: got %v, want %v", tt.season, tt.episode, got, tt.want)
+		}
+	}
+
+	for res, want := range map[Resolution][]int{Res1080p: {3, 2}, Res720p: {4, 1}, Res2160p: {}} {
+		if got := ids(idx.ByResolution(res)); !slices.Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", res, got, want)
+		}
+	}
+
+	// Shared hashes resolve to the newest torrent, whatever the encoding of the hash.
+	for _, h := range []string{hash, strings.ToUpper(hash), "VPG66AJDIVTYTK6N54ASGRLHRGV433YB"} {
+		if torrent, ok := idx.ByHash(h); !ok || torrent.ID != 4 {
+			t.Errorf("%s: got torrent %d, %t, want 4", h, torrent.ID, ok)
+		}
+	}
+	for _, h := range []string{"", "nothex", "2222222222222222222222222222222222222222"} {
+		if torrent, ok := idx.ByHash(h); ok {
+			t.Errorf("%q: got torrent %d, want none", h, torrent.ID)
+		}
+	}
+
+	if _, err := testClient(t, api).BuildIndex(context.Background(), ""); err == nil {
+		t.Fatal("indexed a show without an IMDb ID")
+	}
+}
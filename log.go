@@ -0,0 +1,23 @@
+package eztv
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+)
+
+// discardHandler is the slog.Handler of clients without a logger. It is disabled for every level,
+// so log calls return before building their records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// logQuery is a query logged in its encoded form. It is only encoded if the log is written.
+type logQuery url.Values
+
+func (q logQuery) LogValue() slog.Value {
+	return slog.StringValue(url.Values(q).Encode())
+}
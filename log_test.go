@@ -0,0 +1,87 @@
+package eztv
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer that can be written by the stream goroutine while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestLogger(level slog.Level) (*slog.Logger, *syncBuffer) {
+	var buf syncBuffer
+	return slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: level})), &buf
+}
+
+func TestWithLogger(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelDebug)
+	c := testClient(t, newFakeAPI(3), WithLogger(logger), WithClientName("tenant"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", RecheckInterval: 10 * time.Millisecond})
+	for range 3 {
+		if s := receive(t, stream); s.Err != nil {
+			t.Fatal(s.Err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "rechecking stream") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	closed(t, stream)
+
+	out := buf.String()
+	for _, want := range []string{
+		`msg="stream started" client=tenant imdb_id=1 last_torrent_id=0`,
+		`msg="fetched torrents" client=tenant query="imdb_id=1&limit=1&page=1" torrents=1 torrents_count=3`,
+		`msg="full re-sync started" client=tenant imdb_id=1 torrents_count=3 pages=1`,
+		`msg="full re-sync fetched page" client=tenant imdb_id=1 page=1 pages=1 torrents=3`,
+		`msg="full re-sync finished" client=tenant imdb_id=1 last_torrent_id=3`,
+		`msg="rechecking stream" client=tenant imdb_id=1 last_torrent_id=3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log is missing %s\n%s", want, out)
+		}
+	}
+}
+
+func TestWithLoggerNil(t *testing.T) {
+	c := testClient(t, newFakeAPI(3), WithLogger(nil), WithClientName("tenant"))
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStrict(WithLogger(nil)); err != nil {
+		t.Fatalf("NewStrict rejected a nil logger: %v", err)
+	}
+}
+
+func TestLogQuery(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+	logger.Debug("hidden", "query", logQuery{"page": {"1"}})
+	logger.Info("shown", "query", logQuery{"page": {"1"}, "limit": {"10"}})
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, `query="limit=10&page=1"`) {
+		t.Fatalf("unexpected log %s", out)
+	}
+}
@@ -0,0 +1,261 @@
+package eztv
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrMissingHash   = errors.New("missing torrent hash")
+	ErrInvalidMagnet = errors.New("invalid magnet url")
+)
+
+// TrackerProvider provides the list of trackers used when building magnet links.
+type TrackerProvider interface {
+	Trackers(ctx context.Context) ([]string, error)
+}
+
+// StaticTrackers is a TrackerProvider that always returns the same list of trackers.
+type StaticTrackers []string
+
+// Trackers returns the static list of trackers.
+func (s StaticTrackers) Trackers(context.Context) ([]string, error) {
+	return s, nil
+}
+
+// DefaultTrackers is the TrackerProvider used by clients that are not configured with WithTrackerProvider.
+var DefaultTrackers = StaticTrackers{
+	"udp://tracker.opentrackr.org:1337/announce",
+	"udp://open.stealth.si:80/announce",
+	"udp://tracker.torrent.eu.org:451/announce",
+	"udp://exodus.desync.com:6969/announce",
+	"udp://tracker.openbittorrent.com:6969/announce",
+}
+
+// BuildMagnet builds a magnet link for the torrent from its hash and title.
+//
+// If no trackers are given, the ones from the client's TrackerProvider are used.
+// If the torrent has no hash, it returns ErrMissingHash.
+func (c *Client) BuildMagnet(ctx context.Context, t Torrent, trackers ...string) (string, error) {
+	if t.Hash == "" {
+		return "", ErrMissingHash
+	}
+
+	if len(trackers) == 0 {
+		var err error
+		trackers, err = c.trackers.Trackers(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("magnet:?xt=urn:btih:")
+	sb.WriteString(t.Hash)
+	if t.Title != "" {
+		sb.WriteString("&dn=")
+		sb.WriteString(url.QueryEscape(t.Title))
+	}
+	for _, tracker := range trackers {
+		sb.WriteString("&tr=")
+		sb.WriteString(url.QueryEscape(tracker))
+	}
+
+	return sb.String(), nil
+}
+
+const btihPrefix = "urn:btih:"
+
+// canonicalHash converts a 40 character hex or a 32 character base32 info hash
+// into lowercase hex. It reports false if the hash is in neither form.
+func canonicalHash(hash string) (string, bool) {
+	switch len(hash) {
+	case 40:
+		if _, err := hex.DecodeString(hash); err != nil {
+			return "", false
+		}
+		return strings.ToLower(hash), true
+	case 32:
+		b, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash))
+		if err != nil {
+			return "", false
+		}
+		return hex.EncodeToString(b), true
+	}
+
+	return "", false
+}
+
+// canonicalizeHashes rewrites the torrent's hash and the info hash of its magnet link into lowercase hex.
+// Hashes that are not recognized are left as they are.
+func canonicalizeHashes(t *Torrent) {
+	if hash, ok := canonicalHash(t.Hash); ok {
+		t.Hash = hash
+	}
+
+	t.MagnetURL = canonicalMagnet(t.MagnetURL)
+}
+
+// canonicalMagnet returns the magnet link with its info hash in canonical form.
+func canonicalMagnet(magnet string) string {
+	start, end, ok := magnetHashBounds(magnet)
+	if !ok {
+		return magnet
+	}
+
+	if hash, ok := canonicalHash(magnet[start:end]); ok {
+		return magnet[:start] + hash + magnet[end:]
+	}
+	return magnet
+}
+
+// magnetHashBounds returns the position of the info hash within the magnet link.
+func magnetHashBounds(magnet string) (start, end int, ok bool) {
+	start = strings.Index(magnet, btihPrefix)
+	if start == -1 {
+		return 0, 0, false
+	}
+	start += len(btihPrefix)
+
+	end = strings.IndexByte(magnet[start:], '&')
+	if end == -1 {
+		return start, len(magnet), true
+	}
+	return start, start + end, true
+}
+
+// GetTorrentsByHash works like GetTorrents, but returns the torrents of the page keyed by their
+// info hash in lowercase hex. The hash is taken from the Hash field, falling back to the magnet link.
+//
+// Torrents without a recognizable hash are skipped. If several torrents share a hash,
+// the first one returned by the API, i.e. the newest, is kept.
+func (c *Client) GetTorrentsByHash(ctx context.Context, urlOptions URLOptions) (map[string]Torrent, error) {
+	page, err := c.GetTorrents(ctx, urlOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	byHash := make(map[string]Torrent, len(page.Torrents))
+	for _, torrent := range page.Torrents {
+		hash, ok := torrentHash(torrent)
+		if !ok {
+			continue
+		}
+
+		if _, ok := byHash[hash]; !ok {
+			byHash[hash] = torrent
+		}
+	}
+
+	return byHash, nil
+}
+
+// InfoHash returns the BitTorrent info hash of the torrent in lowercase hex, parsed from
+// the xt=urn:btih parameter of MagnetURL, given either in hex or base32.
+// It returns ErrMissingMagnet if MagnetURL is empty, and an error wrapping ErrInvalidMagnet
+// if it holds no valid info hash.
+func (t Torrent) InfoHash() (string, error) {
+	if t.MagnetURL == "" {
+		return "", ErrMissingMagnet
+	}
+
+	start, end, ok := magnetHashBounds(t.MagnetURL)
+	if !ok {
+		return "", fmt.Errorf("%w: no info hash", ErrInvalidMagnet)
+	}
+	hash, ok := canonicalHash(t.MagnetURL[start:end])
+	if !ok {
+		return "", fmt.Errorf("%w: malformed info hash %q", ErrInvalidMagnet, t.MagnetURL[start:end])
+	}
+	return hash, nil
+}
+
+// Trackers returns the decoded tr parameters of MagnetURL.
+// It returns nil if MagnetURL is empty or holds no trackers.
+func (t Torrent) Trackers() []string {
+	return magnetTrackers(t.MagnetURL)
+}
+
+// torrentHash returns the info hash of the torrent in lowercase hex, taken from the Hash field
+// and falling back to the magnet link.
+func torrentHash(t Torrent) (string, bool) {
+	if hash, ok := canonicalHash(t.Hash); ok {
+		return hash, true
+	}
+
+	hash, err := t.InfoHash()
+	return hash, err == nil
+}
+
+// CanonicalByHash collapses the torrents sharing an info hash, e.g. the copies of a release
+// listed by different mirrors under different IDs, into a single canonical torrent: the one with
+// the most seeds, and then the newest release date. The trackers of every copy are merged into the
+// magnet link of the canonical torrent.
+//
+// Torrents are returned in the order their info hash first appears. Torrents without
+// a recognizable info hash cannot be matched and are returned as they are.
+func CanonicalByHash(torrents []Torrent) []Torrent {
+	var (
+		canonical []Torrent
+		byHash    = make(map[string]int)
+		trackers  = make(map[string][]string)
+	)
+	for _, torrent := range torrents {
+		hash, ok := torrentHash(torrent)
+		if !ok {
+			canonical = append(canonical, torrent)
+			continue
+		}
+
+		for _, tracker := range torrent.Trackers() {
+			if !slices.Contains(trackers[hash], tracker) {
+				trackers[hash] = append(trackers[hash], tracker)
+			}
+		}
+
+		i, ok := byHash[hash]
+		if !ok {
+			byHash[hash] = len(canonical)
+			canonical = append(canonical, torrent)
+			continue
+		}
+		current := &canonical[i]
+		if torrent.CompareTo(*current, SortKeySeeds, SortKeyDate) > 0 {
+			torrent, *current = *current, torrent
+		}
+		if current.MagnetURL == "" {
+			// Keep a magnet link to merge the trackers into, even if the canonical copy has none.
+			current.MagnetURL = torrent.MagnetURL
+		}
+	}
+
+	for hash, i := range byHash {
+		canonical[i].MagnetURL = withTrackers(canonical[i].MagnetURL, trackers[hash])
+	}
+	return canonical
+}
+
+// withTrackers adds the trackers that the magnet link does not list yet to it.
+func withTrackers(magnet string, trackers []string) string {
+	if magnet == "" {
+		return ""
+	}
+
+	listed := magnetTrackers(magnet)
+	var sb strings.Builder
+	sb.WriteString(magnet)
+	for _, tracker := range trackers {
+		if slices.Contains(listed, tracker) {
+			continue
+		}
+		sb.WriteString("&tr=")
+		sb.WriteString(url.QueryEscape(tracker))
+	}
+	return sb.String()
+}
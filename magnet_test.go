@@ -0,0 +1,192 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// failingTrackers is a TrackerProvider that always fails.
+type failingTrackers struct{}
+
+func (failingTrackers) Trackers(context.Context) ([]string, error) {
+	return nil, errors.New("tracker list unavailable")
+}
+
+func TestBuildMagnetTrackerProvider(t *testing.T) {
+	torrent := Torrent{Hash: "abcdef0123456789abcdef0123456789abcdef01", Title: "Show S01E01"}
+	provided := StaticTrackers{"udp://tracker.example:1337/announce", "https://tracker.example/announce"}
+	c := New(WithTrackerProvider(provided))
+
+	magnet, err := c.BuildMagnet(context.Background(), torrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "magnet:?xt=urn:btih:" + torrent.Hash + "&dn=Show+S01E01" +
+		"&tr=udp%3A%2F%2Ftracker.example%3A1337%2Fannounce&tr=https%3A%2F%2Ftracker.example%2Fannounce"; magnet != want {
+		t.Fatalf("got %s, want %s", magnet, want)
+	}
+	if trackers := magnetTrackers(magnet); !slices.Equal(trackers, provided) {
+		t.Fatalf("got trackers %v, want %v", trackers, provided)
+	}
+
+	// Explicit trackers take precedence over the provider.
+	magnet, err = New(WithTrackerProvider(failingTrackers{})).BuildMagnet(context.Background(), torrent, "udp://explicit.example:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trackers := magnetTrackers(magnet); !slices.Equal(trackers, []string{"udp://explicit.example:80"}) {
+		t.Fatalf("got trackers %v, want only the explicit one", trackers)
+	}
+
+	magnet, err = New().BuildMagnet(context.Background(), torrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trackers := magnetTrackers(magnet); !slices.Equal(trackers, DefaultTrackers) {
+		t.Fatalf("got trackers %v, want DefaultTrackers", trackers)
+	}
+
+	if _, err := New(WithTrackerProvider(failingTrackers{})).BuildMagnet(context.Background(), torrent); err == nil {
+		t.Fatal("a failing provider did not fail BuildMagnet")
+	}
+	if _, err := c.BuildMagnet(context.Background(), Torrent{}); !errors.Is(err, ErrMissingHash) {
+		t.Fatalf("got %v, want ErrMissingHash", err)
+	}
+}
+
+func TestWithCanonicalHashes(t *testing.T) {
+	const hex = "abcdef0123456789abcdef0123456789abcdef01"
+	// base32 is the 32 character base32 form of hex.
+	const base32 = "VPG66AJDIVTYTK6N54ASGRLHRGV433YB"
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit, torrents: []Torrent{
+		{ID: 4, Hash: "ABCDEF0123456789abcdef0123456789ABCDEF01", MagnetURL: "magnet:?xt=urn:btih:ABCDEF0123456789abcdef0123456789ABCDEF01&dn=mixed"},
+		{ID: 3, Hash: base32, MagnetURL: "magnet:?xt=urn:btih:" + base32},
+		{ID: 2, Hash: strings.ToLower(base32), MagnetURL: "magnet:?dn=lower&xt=urn:btih:" + strings.ToLower(base32) + "&tr=udp%3A%2F%2Ft"},
+		{ID: 1, Hash: "not-a-hash", MagnetURL: "magnet:?xt=urn:btih:not-a-hash"},
+	}}
+
+	page, err := testClient(t, api, WithCanonicalHashes()).GetTorrents(context.Background(), URLOptions{Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Torrent{
+		{ID: 4, Hash: hex, MagnetURL: "magnet:?xt=urn:btih:" + hex + "&dn=mixed"},
+		{ID: 3, Hash: hex, MagnetURL: "magnet:?xt=urn:btih:" + hex},
+		{ID: 2, Hash: hex, MagnetURL: "magnet:?dn=lower&xt=urn:btih:" + hex + "&tr=udp%3A%2F%2Ft"},
+		{ID: 1, Hash: "not-a-hash", MagnetURL: "magnet:?xt=urn:btih:not-a-hash"},
+	}
+	if !slices.Equal(page.Torrents, want) {
+		t.Fatalf("got %+v, want %+v", page.Torrents, want)
+	}
+
+	page, err = testClient(t, api).GetTorrents(context.Background(), URLOptions{Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(page.Torrents, api.torrents) {
+		t.Fatalf("got %+v, want the hashes untouched without WithCanonicalHashes", page.Torrents)
+	}
+}
+
+func TestGetTorrentsByHash(t *testing.T) {
+	const hex = "abcdef0123456789abcdef0123456789abcdef01"
+	const other = "0123456789abcdef0123456789abcdef01234567"
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit, torrents: []Torrent{
+		{ID: 5, Hash: strings.ToUpper(hex)},
+		{ID: 4, Hash: "VPG66AJDIVTYTK6N54ASGRLHRGV433YB"}, // The base32 form of hex, older, so collapsed into 5.
+		{ID: 3, MagnetURL: "magnet:?xt=urn:btih:" + strings.ToUpper(other)},
+		{ID: 2},
+		{ID: 1, Hash: "not-a-hash"},
+	}}
+
+	byHash, err := testClient(t, api).GetTorrentsByHash(context.Background(), URLOptions{Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int, len(byHash))
+	for hash, torrent := range byHash {
+		got[hash] = torrent.ID
+	}
+	if want := map[string]int{hex: 5, other: 3}; !maps.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestInfoHash(t *testing.T) {
+	const hash = "abcdef0123456789abcdef0123456789abcdef01"
+	tests := []struct {
+		magnet  string
+		want    string
+		wantErr error
+	}{
+		{"magnet:?xt=urn:btih:" + hash + "&dn=Show", hash, nil},
+		{"magnet:?xt=urn:btih:" + strings.ToUpper(hash), hash, nil},
+		{"magnet:?xt=urn:btih:VPG66AJDIVTYTK6N54ASGRLHRGV433YB&tr=udp%3A%2F%2Ftracker.example%3A1337", hash, nil},
+		{"magnet:?dn=Show&xt=urn:btih:vpg66ajdivtytk6n54asgrlhrgv433yb", hash, nil},
+		{"", "", ErrMissingMagnet},
+		{"magnet:?dn=Show", "", ErrInvalidMagnet},
+		{"magnet:?xt=urn:btih:abcdef&dn=Show", "", ErrInvalidMagnet},
+		{"magnet:?xt=urn:btih:" + strings.Repeat("z", 40), "", ErrInvalidMagnet},
+	}
+	for _, tt := range tests {
+		got, err := Torrent{MagnetURL: tt.magnet}.InfoHash()
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q: got %q, %v, want %q, %v", tt.magnet, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTrackers(t *testing.T) {
+	magnet := "magnet:?xt=urn:btih:abcdef0123456789abcdef0123456789abcdef01&dn=Show" +
+		"&tr=udp%3A%2F%2Ftracker.example%3A1337%2Fannounce&tr=http://tracker.example/announce"
+	want := []string{"udp://tracker.example:1337/announce", "http://tracker.example/announce"}
+	if got := (Torrent{MagnetURL: magnet}).Trackers(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for _, magnet := range []string{"", "magnet:?xt=urn:btih:abcdef0123456789abcdef0123456789abcdef01"} {
+		if got := (Torrent{MagnetURL: magnet}).Trackers(); got != nil {
+			t.Errorf("%q: got %v, want nil", magnet, got)
+		}
+	}
+}
+
+func TestCanonicalByHash(t *testing.T) {
+	const hash = "abcdef0123456789abcdef0123456789abcdef01"
+	magnet := func(trackers ...string) string {
+		m := "magnet:?xt=urn:btih:" + hash
+		for _, tracker := range trackers {
+			m += "&tr=" + tracker
+		}
+		return m
+	}
+	torrents := []Torrent{
+		{ID: 1, Hash: hash, Seeds: 5, MagnetURL: magnet("udp%3A%2F%2Fa.example%3A80")},
+		{ID: 2, Hash: "VPG66AJDIVTYTK6N54ASGRLHRGV433YB", Seeds: 10, DateReleasedUnix: 100, MagnetURL: magnet("udp%3A%2F%2Fb.example%3A80", "udp%3A%2F%2Fa.example%3A80")},
+		{ID: 3, Title: "no hash"},
+		{ID: 4, Hash: "1111111111111111111111111111111111111111", Seeds: 1},
+		{ID: 5, Hash: strings.ToUpper(hash), Seeds: 10, DateReleasedUnix: 200},
+		{ID: 6, MagnetURL: magnet("udp%3A%2F%2Fc.example%3A80"), Seeds: 1},
+	}
+
+	got := CanonicalByHash(torrents)
+	if got := ids(got); !slices.Equal(got, []int{5, 3, 4}) {
+		t.Fatalf("got %v, want [5 3 4]", got)
+	}
+	// The most seeded copy wins, and then the newest one. It takes the trackers of every copy.
+	trackers := got[0].Trackers()
+	slices.Sort(trackers)
+	if want := []string{"udp://a.example:80", "udp://b.example:80", "udp://c.example:80"}; !slices.Equal(trackers, want) {
+		t.Fatalf("got trackers %v, want %v", trackers, want)
+	}
+	if got[1] != torrents[2] || got[2] != torrents[3] {
+		t.Fatalf("got %+v, want torrents without copies as they are", got[1:])
+	}
+
+	if got := CanonicalByHash(nil); len(got) != 0 {
+		t.Fatalf("got %v for no torrents", got)
+	}
+}
@@ -0,0 +1,19 @@
+package eztv
+
+import "time"
+
+// Metrics receives measurements of the client's activity, e.g. to export them
+// to a monitoring system. Every measurement is labeled with the client name set
+// with WithClientName.
+type Metrics interface {
+	// ObserveRequest is called after every request to the API with the time it took
+	// and the error it failed with, if any.
+	ObserveRequest(client string, elapsed time.Duration, err error)
+	// AddTorrentsEmitted is called when torrents are pushed onto a stream.
+	AddTorrentsEmitted(client string, n int)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRequest(string, time.Duration, error) {}
+func (noopMetrics) AddTorrentsEmitted(string, int)              {}
@@ -0,0 +1,170 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SameMirror reports whether the two base URLs point at the same EZTV mirror.
+// Scheme and host are compared case-insensitively and trailing slashes are ignored,
+// so "https://EZTV.re/api/" and "https://eztv.re/api" are the same mirror.
+func SameMirror(a, b string) bool {
+	return normalizeMirror(a) == normalizeMirror(b)
+}
+
+// normalizeMirror returns the canonical form of a mirror base URL.
+func normalizeMirror(mirror string) string {
+	mirror = strings.TrimSpace(mirror)
+	u, err := url.Parse(mirror)
+	if err != nil || u.Host == "" {
+		return strings.TrimRight(mirror, "/")
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+// ErrTorrentNotFound is returned by AggregatedStats when no mirror has the torrent.
+var ErrTorrentNotFound = errors.New("torrent not found")
+
+// CompareMirrors asks every mirror configured WithMirrors, concurrently, for the newest torrent ID
+// of the show, and returns them keyed by mirror URL, to tell which mirrors are lagging behind.
+// A mirror without any torrents of the show reports 0.
+//
+// Mirrors that fail are left out of the map, and their errors are returned joined
+// together with the IDs of the others.
+func (c *Client) CompareMirrors(ctx context.Context, imdbID string) (map[string]int, error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return nil, ErrMissingImdbID
+	}
+
+	var mu sync.Mutex
+	newest := make(map[string]int, len(c.mirrors))
+	err := c.forEachMirror(func(mirror string) error {
+		page, err := c.getMirrorTorrents(ctx, mirror, URLOptions{
+			ImdbID: imdbID,
+			Page:   1,
+			Limit:  1,
+		})
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		newest[mirror] = 0
+		if len(page.Torrents) > 0 {
+			newest[mirror] = page.Torrents[0].ID
+		}
+		return nil
+	})
+
+	return newest, err
+}
+
+// AggregatedStats asks every mirror configured WithMirrors, concurrently, for the torrent of the show
+// with the given ID, and returns the most seeds and peers any of them reports, since any mirror seeing
+// peers means they exist. Each mirror is paged through until the torrent is found or passed.
+//
+// Mirrors that fail or do not have the torrent are skipped. If none of them has it, the errors
+// of the failed mirrors are returned joined, or ErrTorrentNotFound if none failed.
+func (c *Client) AggregatedStats(ctx context.Context, imdbID string, id int) (seeds, peers int, err error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return 0, 0, ErrMissingImdbID
+	}
+
+	var (
+		mu    sync.Mutex
+		found bool
+	)
+	err = c.forEachMirror(func(mirror string) error {
+		torrent, ok, err := c.findMirrorTorrent(ctx, mirror, imdbID, id)
+		if err != nil || !ok {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		found = true
+		seeds, peers = max(seeds, torrent.Seeds), max(peers, torrent.Peers)
+		return nil
+	})
+	switch {
+	case found:
+		return seeds, peers, nil
+	case err != nil:
+		return 0, 0, err
+	}
+	return 0, 0, fmt.Errorf("%w: %d", ErrTorrentNotFound, id)
+}
+
+// findMirrorTorrent pages through the torrents of the show on the mirror until it finds the one
+// with the given ID, or passes it, relying on torrents being in descending ID order.
+func (c *Client) findMirrorTorrent(ctx context.Context, mirror, imdbID string, id int) (Torrent, bool, error) {
+	for i := 1; ; i++ {
+		page, err := c.getMirrorTorrents(ctx, mirror, URLOptions{
+			ImdbID: imdbID,
+			Page:   i,
+			Limit:  MaxEZTVAPILimit,
+		})
+		if err != nil {
+			return Torrent{}, false, err
+		}
+
+		for _, torrent := range page.Torrents {
+			if torrent.ID == id {
+				return torrent, true, nil
+			}
+			if torrent.ID < id {
+				return Torrent{}, false, nil
+			}
+		}
+		if len(page.Torrents) == 0 || i >= page.lastPage(MaxEZTVAPILimit) {
+			return Torrent{}, false, nil
+		}
+	}
+}
+
+// getMirrorTorrents works like GetTorrents, but only asks the given mirror, without failing over to the others.
+func (c *Client) getMirrorTorrents(ctx context.Context, mirror string, urlOptions URLOptions) (*Page, error) {
+	req, err := c.newTorrentsRequest(ctx, mirror, urlOptions)
+	if err != nil {
+		return nil, err
+	}
+	page, meta, err := doJSON[Page](c, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.processPage(ctx, page, meta, urlOptions); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// forEachMirror calls fn for every configured mirror concurrently, and returns the errors
+// it failed with, joined in the order of the mirrors and labeled with them.
+func (c *Client) forEachMirror(fn func(mirror string) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(c.mirrors))
+	for i, mirror := range c.mirrors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(mirror); err != nil {
+				errs[i] = fmt.Errorf("mirror %s: %w", mirror, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
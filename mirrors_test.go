@@ -0,0 +1,189 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// testMirrors starts a test server for every handler and returns their URLs.
+func testMirrors(t *testing.T, handlers ...http.Handler) []string {
+	t.Helper()
+	var urls []string
+	for _, h := range handlers {
+		srv := httptest.NewServer(h)
+		t.Cleanup(srv.Close)
+		urls = append(urls, srv.URL)
+	}
+	return urls
+}
+
+var failingMirror = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "down", http.StatusBadGateway)
+})
+
+func TestSameMirror(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://eztv.re/api", "https://eztv.re/api", true},
+		{"https://EZTV.re/api/", "https://eztv.re/api", true},
+		{"HTTPS://eztv.re/api", "https://eztv.re/api//", true},
+		{" https://eztv.re/api ", "https://eztv.re/api", true},
+		{"https://eztv.re/api", "http://eztv.re/api", false},
+		{"https://eztv.re/api", "https://eztv.re/API", false},
+		{"https://eztv.re/api", "https://eztvx.to/api", false},
+		{"https://eztv.re:8443/api", "https://eztv.re/api", false},
+		{"localhost/api/", "localhost/api", true},
+	}
+	for _, tt := range tests {
+		if got := SameMirror(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameMirror(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareMirrors(t *testing.T) {
+	mirrors := testMirrors(t, newFakeAPI(10), newFakeAPI(7), &fakeAPI{maxLimit: MaxEZTVAPILimit}, failingMirror)
+	c := New(WithMirrors(mirrors...))
+
+	newest, err := c.CompareMirrors(context.Background(), "tt1")
+	want := map[string]int{mirrors[0]: 10, mirrors[1]: 7, mirrors[2]: 0}
+	if !maps.Equal(newest, want) {
+		t.Errorf("got %v, want %v", newest, want)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("got error %v, want the error of the failing mirror", err)
+	}
+}
+
+func TestCompareMirrorsAllHealthy(t *testing.T) {
+	mirrors := testMirrors(t, newFakeAPI(3), newFakeAPI(3))
+	newest, err := New(WithMirrors(mirrors...)).CompareMirrors(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newest) != 2 || newest[mirrors[0]] != 3 || newest[mirrors[1]] != 3 {
+		t.Fatalf("got %v", newest)
+	}
+}
+
+func TestAggregatedStats(t *testing.T) {
+	withStats := func(seeds, peers int) *fakeAPI {
+		api := newFakeAPI(250)
+		// Torrent 120 is on the second page.
+		api.torrents[250-120].Seeds, api.torrents[250-120].Peers = seeds, peers
+		return api
+	}
+	lacking := newFakeAPI(250)
+	lacking.torrents = append(lacking.torrents[:250-120], lacking.torrents[250-120+1:]...)
+
+	mirrors := testMirrors(t, withStats(10, 3), withStats(4, 8), lacking, failingMirror)
+	seeds, peers, err := New(WithMirrors(mirrors...)).AggregatedStats(context.Background(), "tt1", 120)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seeds != 10 || peers != 8 {
+		t.Fatalf("got %d seeds and %d peers, want 10 and 8", seeds, peers)
+	}
+}
+
+func TestAggregatedStatsNotFound(t *testing.T) {
+	mirrors := testMirrors(t, newFakeAPI(5), newFakeAPI(5))
+	_, _, err := New(WithMirrors(mirrors...)).AggregatedStats(context.Background(), "tt1", 6)
+	if !errors.Is(err, ErrTorrentNotFound) {
+		t.Fatalf("got %v, want ErrTorrentNotFound", err)
+	}
+
+	mirrors = testMirrors(t, newFakeAPI(5), failingMirror)
+	_, _, err = New(WithMirrors(mirrors...)).AggregatedStats(context.Background(), "tt1", 6)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want the error of the failing mirror", err)
+	}
+}
+
+func TestWithMirrors(t *testing.T) {
+	api := newFakeAPI(3)
+	var downHits, aHits, bHits atomic.Int32
+	var aDown, allDown atomic.Bool
+	mirror := func(hits *atomic.Int32, down func() bool) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			if down() || allDown.Load() {
+				failingMirror(w, r)
+				return
+			}
+			api.ServeHTTP(w, r)
+		})
+	}
+	urls := testMirrors(t,
+		mirror(&downHits, func() bool { return true }),
+		mirror(&aHits, aDown.Load),
+		mirror(&bHits, func() bool { return false }),
+	)
+	c := New(WithMirrors(urls...))
+	get := func() error {
+		_, err := c.GetTorrents(context.Background(), URLOptions{Page: 1})
+		return err
+	}
+	hits := func() [3]int32 { return [3]int32{downHits.Load(), aHits.Load(), bHits.Load()} }
+
+	if err := get(); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits(); got != [3]int32{1, 1, 0} {
+		t.Fatalf("got hits %v, want the first mirror to fail over to the second", got)
+	}
+	// The last good mirror is tried first.
+	if err := get(); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits(); got != [3]int32{1, 2, 0} {
+		t.Fatalf("got hits %v, want only the last good mirror", got)
+	}
+	aDown.Store(true)
+	if err := get(); err != nil {
+		t.Fatal(err)
+	}
+	if err := get(); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits(); got != [3]int32{1, 3, 2} {
+		t.Fatalf("got hits %v, want the third mirror to take over", got)
+	}
+
+	// Requests made at once switch between the mirrors safely.
+	aDown.Store(false)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := get(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	allDown.Store(true)
+	err := get()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || !strings.HasPrefix(err.Error(), "all mirrors failed: ") {
+		t.Fatalf("got %v, want all mirrors failing with a 502", err)
+	}
+	for _, url := range urls {
+		if !strings.Contains(err.Error(), "mirror "+url+": ") {
+			t.Errorf("error %q is missing the failure of %s", err, url)
+		}
+	}
+}
@@ -1,6 +1,13 @@
 package eztv
 
-import "net/http"
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"golang.org/x/time/rate"
+)
 
 type Option func(*Client)
 
@@ -11,9 +18,238 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
-// WithBaseURL sets the base URL that will be used to make requests.
+// WithBaseURL sets the base URL that will be used to make requests, with or without a trailing slash.
+// It replaces any mirrors set with WithMirrors.
 func WithBaseURL(url string) Option {
 	return func(c *Client) {
-		c.baseURL = url
+		c.mirrors = []string{url}
+	}
+}
+
+// WithMirrors sets the base URLs of several EZTV mirrors to fail over between. Every request is sent
+// to the mirror that last responded successfully first, and then to the next ones in the given order
+// until one responds successfully. It replaces the base URL set with WithBaseURL.
+func WithMirrors(urls ...string) Option {
+	return func(c *Client) {
+		c.mirrors = slices.Clone(urls)
+	}
+}
+
+// WithClientName sets the name that identifies the client in logs and metrics.
+// It is useful to tell apart several clients running in the same process.
+func WithClientName(name string) Option {
+	return func(c *Client) {
+		c.name = name
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every API request.
+// By default the header of the underlying http.Client is used.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithTreat404AsEmpty makes GetTorrents return an empty Page instead of an error
+// when the API responds with 404 Not Found. Some EZTV compatible backends respond this way
+// for shows that have no torrents.
+func WithTreat404AsEmpty() Option {
+	return func(c *Client) {
+		c.treat404AsEmpty = true
+	}
+}
+
+// WithIMDbResolver sets the IMDbResolver used to resolve show titles into IMDb IDs
+// for methods such as SearchByTitle.
+func WithIMDbResolver(resolver IMDbResolver) Option {
+	return func(c *Client) {
+		c.resolver = resolver
+	}
+}
+
+// WithVerifyOrdering makes GetTorrents verify that the returned torrents are in descending ID order,
+// as documented by the EZTV API, and sort them if they are not, logging a warning to the logger
+// set WithLogger. Re-sync and other pagination helpers rely on this ordering, so it protects them
+// against mirrors that do not follow it.
+func WithVerifyOrdering() Option {
+	return func(c *Client) {
+		c.verifyOrdering = true
+	}
+}
+
+// WithDecodeRetry makes GetTorrents re-issue the request up to n times when the response body
+// is truncated before it could be fully decoded. Responses that are complete, but fail to decode
+// for any other reason are not retried.
+func WithDecodeRetry(n int) Option {
+	return func(c *Client) {
+		c.decodeRetries = n
+	}
+}
+
+// WithRetry makes GetTorrents make up to maxAttempts attempts at every request that fails
+// with a network error or a 5xx or 429 response. Between attempts it backs off exponentially
+// from baseDelay with random jitter, or for as long as the Retry-After header of a 429 response asks.
+// Once all attempts are exhausted, a *RetryExhaustedError wrapping the error of the last one is returned.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retry = retryPolicy{
+			maxAttempts: maxAttempts,
+			baseDelay:   baseDelay,
+		}
+	}
+}
+
+// WithRateLimit makes GetTorrents wait before every request until the client is allowed
+// to make it, at most r requests per second with bursts of up to burst requests.
+// Requests that would not be allowed before the context deadline fail right away.
+func WithRateLimit(r rate.Limit, burst int) Option {
+	return func(c *Client) {
+		c.limiter = rate.NewLimiter(r, burst)
+	}
+}
+
+// WithRequestTimeout bounds every single HTTP request, including each attempt made WithRetry,
+// to d, independently of the context passed in. A request that times out returns an error
+// wrapping both ErrRequestTimeout and context.DeadlineExceeded, which tells it apart from
+// the cancellation of the context.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.requestTimeout = d
+	}
+}
+
+// WithTrackerProvider sets the TrackerProvider used to build magnet links
+// when no explicit trackers are given. Default is DefaultTrackers.
+func WithTrackerProvider(provider TrackerProvider) Option {
+	return func(c *Client) {
+		c.trackers = provider
+	}
+}
+
+// WithCanonicalHashes makes GetTorrents normalize the Hash of every torrent and the info hash
+// of its magnet link into lowercase 40 character hex, converting base32 hashes where needed.
+// This keeps hashes comparable across mirrors.
+func WithCanonicalHashes() Option {
+	return func(c *Client) {
+		c.canonicalHashes = true
+	}
+}
+
+// WithMetrics sets the Metrics that receive measurements of the client's activity.
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) {
+		c.metrics = metrics
+	}
+}
+
+// WithLogger sets the logger the client writes debug logs of its activity to, such as the pages
+// fetched and the torrents emitted by streams. Logs are tagged with the client name set with
+// WithClientName. Without a logger, or with a nil one, nothing is logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithBackfillImdbID makes GetTorrents fill in the empty ImdbID of returned torrents
+// with the ImdbID they were requested for. Torrents that already have one are left as they are.
+func WithBackfillImdbID() Option {
+	return func(c *Client) {
+		c.backfillImdbID = true
+	}
+}
+
+// WithResultTransforms adds transforms that GetTorrents applies, in the given order, to every decoded torrent.
+//
+// Transforms run after the built-in normalizations (WithCanonicalHashes, WithBackfillImdbID)
+// and before any torrents are filtered out by URLOptions.MinSeeds, the ordering is verified
+// and the page is sorted by URLOptions.Sort, so all of these see the transformed torrents,
+// and so does anything that inspects the page later, e.g. Page.Validate. The client caches
+// no pages, so the transforms run again for every fetched page.
+func WithResultTransforms(transforms ...func(*Torrent)) Option {
+	return func(c *Client) {
+		c.transforms = append(c.transforms, transforms...)
+	}
+}
+
+// WithRecorder makes the client write every API response into a file in dir,
+// keyed by the request URL. The recordings can be served back with WithReplayer.
+func WithRecorder(dir string) Option {
+	return func(c *Client) {
+		c.recordDir = dir
+	}
+}
+
+// WithReplayer makes the client serve API responses from the files recorded into dir with WithRecorder,
+// instead of making requests over the network. Requests without a recording fail.
+func WithReplayer(dir string) Option {
+	return func(c *Client) {
+		c.replayDir = dir
+	}
+}
+
+// WithTrackerTimeout sets how long ValidateTrackers waits for each tracker to respond.
+// Default is DefaultTrackerTimeout.
+func WithTrackerTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.trackerTimeout = timeout
+	}
+}
+
+// WithSharedStreams makes TorrentStream calls for the same ImdbID share a single poller
+// instead of each polling the API. Every subscriber receives the torrents found after it subscribed,
+// and the poller stops once the contexts of all its subscribers are done.
+//
+// The poller is started with the StreamOptions of the first subscriber. Since torrents are handed
+// to subscribers one after another, a slow subscriber holds back the others.
+func WithSharedStreams() Option {
+	return func(c *Client) {
+		c.shared = &sharedStreams{streams: make(map[string]*sharedStream)}
+	}
+}
+
+// WithMaxSearchPages limits the number of pages search helpers, such as SearchByTitle
+// and FindEpisodes, walk through before giving up with ErrSearchTruncated.
+// Default is 0, which walks every page of the show.
+func WithMaxSearchPages(n int) Option {
+	return func(c *Client) {
+		c.maxSearchPages = n
+	}
+}
+
+// WithRequestSigner sets a function that is called with every API request right before it is sent,
+// e.g. to attach the signature headers required by an authenticating gateway.
+// If the signer returns an error, the request is not sent.
+func WithRequestSigner(signer func(*http.Request) error) Option {
+	return func(c *Client) {
+		c.signer = signer
+	}
+}
+
+// WithRequestHook sets a function that is called with every API request right before it is sent,
+// after it was signed. It must not modify the request.
+func WithRequestHook(hook func(req *http.Request)) Option {
+	return func(c *Client) {
+		c.requestHook = hook
+	}
+}
+
+// WithResponseHook sets a function that is called after every API request with its response,
+// or the error that prevented getting one, and how long it took. The hook must not read
+// or close the response body, which is still to be read by the client.
+func WithResponseHook(hook func(resp *http.Response, err error, elapsed time.Duration)) Option {
+	return func(c *Client) {
+		c.responseHook = hook
+	}
+}
+
+// WithStrictInit makes New validate the configuration, e.g. that every base URL parses, the mirrors
+// are distinct and the timeouts are positive. An invalid configuration is logged as an error and
+// returned by every request the client makes, instead of failing in less obvious ways later on.
+// Use NewStrict to get the error from the constructor itself.
+func WithStrictInit() Option {
+	return func(c *Client) {
+		c.strictInit = true
 	}
 }
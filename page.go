@@ -0,0 +1,87 @@
+package eztv
+
+import (
+	"fmt"
+	"math"
+)
+
+// TotalPages returns the number of pages the torrents of the query span with the page's Limit.
+func (p Page) TotalPages() int {
+	return p.lastPage(0)
+}
+
+// effectiveLimit returns the number of torrents per page the API answered with: the page's Limit,
+// or requested if the response did not report one.
+func (p Page) effectiveLimit(requested int) int {
+	if p.Limit > 0 {
+		return p.Limit
+	}
+	return requested
+}
+
+// lastPage returns the number of the last page of the query, with the effective limit of the page.
+// It is 0 if neither the page nor the request have a limit.
+func (p Page) lastPage(requested int) int {
+	limit := p.effectiveLimit(requested)
+	if limit <= 0 {
+		return 0
+	}
+	return pageCount(p.TorrentsCount, limit)
+}
+
+// pageCount returns the number of pages of limit torrents that torrentsCount torrents span.
+func pageCount(torrentsCount, limit int) int {
+	return int(math.Ceil(float64(torrentsCount) / float64(limit)))
+}
+
+// markBeyondLast empties the page and sets BeyondLast, if the requested page is past the last one.
+// Depending on the mirror, such pages come back either empty or with the torrents of another page.
+func markBeyondLast(page *Page, urlOptions URLOptions) {
+	if beyondLast(page, urlOptions) {
+		page.Torrents = []Torrent{}
+		page.BeyondLast = true
+	}
+}
+
+// beyondLast reports whether the requested page is past the last one, judging by the page's
+// torrents count and limit only.
+func beyondLast(page *Page, urlOptions URLOptions) bool {
+	if page.TorrentsCount == 0 || urlOptions.Page <= 1 {
+		return false
+	}
+
+	total := page.lastPage(urlOptions.Limit)
+	return total > 0 && urlOptions.Page > total
+}
+
+// Validate checks the internal consistency of the page and returns an error
+// describing the first invariant that does not hold:
+//   - torrents count and page number are not negative,
+//   - limit is between 1 and MaxEZTVAPILimit,
+//   - torrents count is not less than the number of torrents on the page,
+//   - torrent IDs are positive and in descending order.
+func (p Page) Validate() error {
+	if p.TorrentsCount < 0 {
+		return fmt.Errorf("invalid page: negative torrents count %d", p.TorrentsCount)
+	}
+	if p.Page < 0 {
+		return fmt.Errorf("invalid page: negative page number %d", p.Page)
+	}
+	if p.Limit < 1 || p.Limit > MaxEZTVAPILimit {
+		return fmt.Errorf("invalid page: limit %d is out of range [1, %d]", p.Limit, MaxEZTVAPILimit)
+	}
+	if p.TorrentsCount < len(p.Torrents) {
+		return fmt.Errorf("invalid page: torrents count %d is less than the %d torrents on the page", p.TorrentsCount, len(p.Torrents))
+	}
+
+	for i, torrent := range p.Torrents {
+		if torrent.ID <= 0 {
+			return fmt.Errorf("invalid page: torrent at index %d has non-positive ID %d", i, torrent.ID)
+		}
+		if i > 0 && torrent.ID >= p.Torrents[i-1].ID {
+			return fmt.Errorf("invalid page: torrent ID %d at index %d is not lower than the previous ID %d", torrent.ID, i, p.Torrents[i-1].ID)
+		}
+	}
+
+	return nil
+}
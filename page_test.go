@@ -0,0 +1,66 @@
+package eztv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPageValidate(t *testing.T) {
+	valid := Page{TorrentsCount: 40, Limit: 30, Page: 1, Torrents: []Torrent{{ID: 3}, {ID: 2}, {ID: 1}}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid page: %v", err)
+	}
+	if err := (Page{Limit: 30, Torrents: []Torrent{}}).Validate(); err != nil {
+		t.Fatalf("empty page: %v", err)
+	}
+
+	tests := map[string]struct {
+		page Page
+		want string
+	}{
+		"negative count":     {Page{TorrentsCount: -1, Limit: 30}, "negative torrents count"},
+		"negative page":      {Page{Limit: 30, Page: -1}, "negative page number"},
+		"zero limit":         {Page{}, "limit 0 is out of range"},
+		"limit above max":    {Page{Limit: MaxEZTVAPILimit + 1}, "limit 101 is out of range"},
+		"count below length": {Page{TorrentsCount: 1, Limit: 30, Torrents: []Torrent{{ID: 2}, {ID: 1}}}, "less than the 2 torrents"},
+		"non-positive id":    {Page{TorrentsCount: 2, Limit: 30, Torrents: []Torrent{{ID: 2}, {ID: 0}}}, "non-positive ID 0"},
+		"ascending ids":      {Page{TorrentsCount: 2, Limit: 30, Torrents: []Torrent{{ID: 1}, {ID: 2}}}, "ID 2 at index 1 is not lower"},
+		"duplicate ids":      {Page{TorrentsCount: 2, Limit: 30, Torrents: []Torrent{{ID: 2}, {ID: 2}}}, "ID 2 at index 1 is not lower"},
+	}
+	for name, tt := range tests {
+		err := tt.page.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", name, err, tt.want)
+		}
+	}
+}
+
+func TestGetTorrentsBeyondLast(t *testing.T) {
+	// Some mirrors return the first page for pages past the last one.
+	firstPage := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Page{TorrentsCount: 3, Limit: 30, Page: 1, Torrents: torrentsWithIDs(1, 3)})
+	})
+	for name, h := range map[string]http.Handler{"empty": newFakeAPI(3), "first page": firstPage} {
+		page, err := testClient(t, h).GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 999, Limit: 30})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !page.BeyondLast || len(page.Torrents) != 0 || page.TorrentsCount != 3 {
+			t.Fatalf("%s: got %+v, want an empty page beyond the last one", name, page)
+		}
+	}
+
+	page, err := testClient(t, newFakeAPI(3)).GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 1, Limit: 30})
+	if err != nil || page.BeyondLast || len(page.Torrents) != 3 {
+		t.Fatalf("got %+v, %v for the last page, want its torrents", page, err)
+	}
+
+	// A show without any torrents is empty, not beyond its last page.
+	page, err = testClient(t, &fakeAPI{maxLimit: MaxEZTVAPILimit}).GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 999, Limit: 30})
+	if err != nil || page.BeyondLast || len(page.Torrents) != 0 {
+		t.Fatalf("got %+v, %v for a show without torrents, want an empty page", page, err)
+	}
+}
@@ -0,0 +1,125 @@
+package eztv
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// ParseSource selects which Torrent fields the parsing helpers, such as EpisodeRange,
+// read the release information from.
+type ParseSource int32
+
+const (
+	// ParseTitleFirst parses the Title, falling back to the Filename when nothing is found in it.
+	ParseTitleFirst ParseSource = iota
+	// ParseFilenameFirst parses the Filename, falling back to the Title when nothing is found in it.
+	ParseFilenameFirst
+	// ParseBestOfBoth parses both fields and picks the more informative result.
+	ParseBestOfBoth
+)
+
+var parseSource atomic.Int32
+
+// SetParseSource sets the ParseSource used by all parsing helpers. Default is ParseTitleFirst.
+func SetParseSource(source ParseSource) {
+	parseSource.Store(int32(source))
+}
+
+// parseTorrent runs parse over the torrent fields selected by the current ParseSource.
+// With ParseBestOfBoth, better reports whether result a is more informative than b.
+func parseTorrent[T any](t Torrent, parse func(s string) (T, bool), better func(a, b T) bool) (T, bool) {
+	source := ParseSource(parseSource.Load())
+
+	first, second := t.Title, t.Filename
+	if source == ParseFilenameFirst {
+		first, second = second, first
+	}
+
+	a, aOK := parse(first)
+	if source != ParseBestOfBoth && aOK {
+		return a, true
+	}
+	b, bOK := parse(second)
+
+	switch {
+	case aOK && bOK:
+		if better(b, a) {
+			return b, true
+		}
+		return a, true
+	case aOK:
+		return a, true
+	}
+	return b, bOK
+}
+
+// containers are the video container formats recognized by Container.
+var containers = []string{"mkv", "mp4", "avi", "m4v", "mov", "wmv", "webm"}
+
+var containerRe = regexp.MustCompile(`(?i)\b(` + strings.Join(containers, "|") + `)\b`)
+
+// Container returns the likely video container of the torrent, e.g. "mkv" or "mp4",
+// or an empty string when it is unknown.
+//
+// The extension of Filename is used when it is a known container, otherwise the container
+// is looked up in the fields selected by SetParseSource.
+func (t Torrent) Container() string {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(t.Filename), "."))
+	for _, container := range containers {
+		if ext == container {
+			return container
+		}
+	}
+
+	container, _ := parseTorrent(t, func(s string) (string, bool) {
+		m := containerRe.FindStringSubmatch(s)
+		if m == nil {
+			return "", false
+		}
+		return strings.ToLower(m[1]), true
+	}, func(a, b string) bool { return false })
+	return container
+}
+
+var (
+	// releaseSuffixRe matches site tags and the file extension trailing a release name.
+	releaseSuffixRe = regexp.MustCompile(`(?i)(\s*\[[^\]]*\]|\s+eztv(x)?(\.\w+)?|\.(` + strings.Join(containers, "|") + `))+\s*$`)
+	releaseGroupRe  = regexp.MustCompile(`-([A-Za-z0-9]+)$`)
+	// releaseExtRe matches the file extension of a release name.
+	releaseExtRe = regexp.MustCompile(`(?i)\.(` + strings.Join(containers, "|") + `)\s*$`)
+	// releaseSiteTagRe matches the site tags in a release name, e.g. "[eztv]", "[TorrentCouch.com]" or a trailing " EZTV".
+	releaseSiteTagRe = regexp.MustCompile(`(?i)\[[^\]]*\b(eztvx?|\w+\.(com|net|org|re|to|ag|io|tv|se|me|cc))\b[^\]]*\]|\seztvx?(\.\w+)?\s*$`)
+	// releaseSeparatorRe matches the runs of characters separating the words of a release name.
+	releaseSeparatorRe = regexp.MustCompile(`[\s._\-+\[\]()]+`)
+	// releaseCodecRe matches codecs split by a separator (e.g. "H 264"), which scene names spell as one word.
+	releaseCodecRe = regexp.MustCompile(`\b([hx]) (26[45])\b`)
+)
+
+// ReleaseGroup returns the release group of the torrent, e.g. "NTb" for
+// "Show.S01E01.1080p.WEB.h264-NTb[eztv].mkv", parsed from the fields selected by SetParseSource.
+// It returns an empty string when no group is found.
+func (t Torrent) ReleaseGroup() string {
+	group, _ := parseTorrent(t, func(s string) (string, bool) {
+		s = releaseSuffixRe.ReplaceAllString(strings.TrimSpace(s), "")
+		m := releaseGroupRe.FindStringSubmatch(s)
+		if m == nil {
+			return "", false
+		}
+		return m[1], true
+	}, func(a, b string) bool { return false })
+	return group
+}
+
+// NormalizeReleaseName canonicalizes a release name so that the scene ("Show.S01E01.1080p.WEB.h264-GROUP")
+// and P2P ("Show S01E01 [1080p WEB H.264] GROUP") naming styles of the same release compare equal.
+// Site tags and the file extension are stripped, and the words are lowercased and separated by single spaces.
+// Filters made with ParseFilter match titles in this form.
+func NormalizeReleaseName(name string) string {
+	name = releaseExtRe.ReplaceAllString(strings.TrimSpace(name), "")
+	name = releaseSiteTagRe.ReplaceAllString(name, " ")
+	name = releaseSeparatorRe.ReplaceAllString(strings.ToLower(name), " ")
+	name = releaseCodecRe.ReplaceAllString(name, "$1$2")
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,70 @@
+package eztv
+
+import "testing"
+
+func TestNormalizeReleaseName(t *testing.T) {
+	tests := []struct {
+		scene, p2p string
+	}{
+		{"Show.S01E01.1080p.WEB.h264-GROUP", "Show S01E01 [1080p WEB H.264] GROUP"},
+		{"Show.S01E01.1080p.WEB.h264-GROUP.mkv", "Show S01E01 1080p WEB H 264 GROUP [eztv]"},
+		{"The.Show.2019.S02E10.720p.HDTV.x264-GRP[TorrentCouch.com]", "The Show (2019) S02E10 720p HDTV x264 - GRP EZTV"},
+	}
+	for _, tt := range tests {
+		scene, p2p := NormalizeReleaseName(tt.scene), NormalizeReleaseName(tt.p2p)
+		if scene != p2p {
+			t.Errorf("%q normalized to %q, but %q to %q", tt.scene, scene, tt.p2p, p2p)
+		}
+	}
+
+	if got, want := NormalizeReleaseName("  Show.S01E01.1080p.WEB.h264-GROUP  "), "show s01e01 1080p web h264 group"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if NormalizeReleaseName("Show.S01E01.720p") == NormalizeReleaseName("Show.S01E01.1080p") {
+		t.Error("different releases normalized equal")
+	}
+}
+
+func TestSetParseSource(t *testing.T) {
+	t.Cleanup(func() { SetParseSource(ParseTitleFirst) })
+	torrent := Torrent{Title: "Show S01E01E02 720p", Filename: "Show.S01E01-E04.1080p.mkv"}
+	titleOnly := Torrent{Title: "Show S01E01E02 720p", Filename: "Show.1080p.mkv"}
+
+	tests := []struct {
+		name    string
+		source  ParseSource
+		torrent Torrent
+		wantEnd int
+	}{
+		{"title wins", ParseTitleFirst, torrent, 2},
+		{"filename wins", ParseFilenameFirst, torrent, 4},
+		{"falls back to the title", ParseFilenameFirst, titleOnly, 2},
+		{"wider range wins", ParseBestOfBoth, torrent, 4},
+		{"wider range wins either way", ParseBestOfBoth, Torrent{Title: torrent.Filename, Filename: torrent.Title}, 4},
+	}
+	for _, tt := range tests {
+		SetParseSource(tt.source)
+		if _, end, ok := tt.torrent.EpisodeRange(); !ok || end != tt.wantEnd {
+			t.Errorf("%s: got end %d, %t, want %d", tt.name, end, ok, tt.wantEnd)
+		}
+	}
+}
+
+func TestContainer(t *testing.T) {
+	tests := []struct {
+		torrent Torrent
+		want    string
+	}{
+		{Torrent{Filename: "Show.S01E01.1080p.WEB.h264-GROUP.mkv", Title: "Show S01E01 MP4"}, "mkv"},
+		{Torrent{Filename: "Show.S01E01.720p.HDTV.x264.MP4"}, "mp4"},
+		{Torrent{Filename: "Show.S01E01.720p.HDTV.x264.nfo", Title: "Show S01E01 720p AVI"}, "avi"},
+		{Torrent{Title: "Show S01E01 720p WEB x264 [mkv]"}, "mkv"},
+		{Torrent{Title: "Show S01E01 720p WEB x264-GROUP"}, ""},
+		{Torrent{Title: "Show.S01E01.Remkvd.720p"}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.torrent.Container(); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.torrent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package prommetrics provides an eztv.Metrics implementation that exports
+// the client's activity as Prometheus metrics.
+//
+// It lives in its own module, so that the eztv package does not depend on the Prometheus client.
+package prommetrics
+
+import (
+	"time"
+
+	"github.com/PauliusLozys/eztv"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ eztv.Metrics = (*PrometheusMetrics)(nil)
+
+// PrometheusMetrics is an eztv.Metrics that records the client's activity into Prometheus collectors.
+// Every collector is labeled with the client name.
+type PrometheusMetrics struct {
+	requests        *prometheus.CounterVec
+	errors          *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	torrentsEmitted *prometheus.CounterVec
+}
+
+// NewPrometheusMetrics creates the collectors and registers them with reg.
+// The result can be passed to eztv.WithMetrics.
+func NewPrometheusMetrics(reg prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "eztv",
+			Name:      "requests_total",
+			Help:      "Number of requests made to the EZTV API.",
+		}, []string{"client"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "eztv",
+			Name:      "request_errors_total",
+			Help:      "Number of requests to the EZTV API that failed.",
+		}, []string{"client"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "eztv",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of requests to the EZTV API.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"client"}),
+		torrentsEmitted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "eztv",
+			Name:      "torrents_emitted_total",
+			Help:      "Number of torrents pushed onto streams.",
+		}, []string{"client"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.errors, m.requestDuration, m.torrentsEmitted} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ObserveRequest records a request, its duration and whether it failed.
+func (m *PrometheusMetrics) ObserveRequest(client string, elapsed time.Duration, err error) {
+	m.requests.WithLabelValues(client).Inc()
+	m.requestDuration.WithLabelValues(client).Observe(elapsed.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(client).Inc()
+	}
+}
+
+// AddTorrentsEmitted records torrents pushed onto a stream.
+func (m *PrometheusMetrics) AddTorrentsEmitted(client string, n int) {
+	m.torrentsEmitted.WithLabelValues(client).Add(float64(n))
+}
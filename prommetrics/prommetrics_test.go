@@ -0,0 +1,79 @@
+package prommetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PauliusLozys/eztv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewPrometheusMetrics(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.ObserveRequest("a", time.Second, nil)
+	m.ObserveRequest("a", time.Second, errors.New("boom"))
+	m.ObserveRequest("b", time.Second, nil)
+	m.AddTorrentsEmitted("a", 3)
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("a")); got != 2 {
+		t.Errorf("requests of a = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("b")); got != 1 {
+		t.Errorf("requests of b = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.errors.WithLabelValues("a")); got != 1 {
+		t.Errorf("errors of a = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.torrentsEmitted.WithLabelValues("a")); got != 3 {
+		t.Errorf("torrents emitted of a = %v, want 3", got)
+	}
+	if got := testutil.CollectAndCount(m.requestDuration); got != 2 {
+		t.Errorf("request duration series = %d, want 2", got)
+	}
+}
+
+func TestPrometheusMetricsDuplicateRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := NewPrometheusMetrics(reg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPrometheusMetrics(reg); err == nil {
+		t.Fatal("expected an error registering the collectors twice")
+	}
+}
+
+func TestPrometheusMetricsWithClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"torrents_count":1,"limit":1,"page":1,"torrents":[{"id":1}]}`)
+	}))
+	defer srv.Close()
+
+	reg := prometheus.NewRegistry()
+	m, err := NewPrometheusMetrics(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := eztv.New(eztv.WithBaseURL(srv.URL), eztv.WithClientName("test"), eztv.WithMetrics(m))
+
+	if _, err := client.GetTorrents(context.Background(), eztv.URLOptions{Page: 1, Limit: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("test")); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.errors.WithLabelValues("test")); got != 0 {
+		t.Errorf("errors = %v, want 0", got)
+	}
+}
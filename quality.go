@@ -0,0 +1,226 @@
+package eztv
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Source is the kind of source a release was made from.
+type Source int
+
+const (
+	SourceUnknown Source = iota
+	SourceHDTV
+	SourceWEBRip
+	SourceWEBDL
+	SourceBluRay
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceHDTV:
+		return "HDTV"
+	case SourceWEBRip:
+		return "WEBRip"
+	case SourceWEBDL:
+		return "WEB-DL"
+	case SourceBluRay:
+		return "BluRay"
+	}
+	return "Unknown"
+}
+
+// sourcePatterns map the markers found in release names to their Source, checked in order.
+var sourcePatterns = []struct {
+	re     *regexp.Regexp
+	source Source
+}{
+	{regexp.MustCompile(`(?i)\b(blu-?ray|bd-?rip|br-?rip)\b`), SourceBluRay},
+	{regexp.MustCompile(`(?i)\bweb-?rip\b`), SourceWEBRip},
+	{regexp.MustCompile(`(?i)\bweb(-?dl)?\b`), SourceWEBDL},
+	{regexp.MustCompile(`(?i)\b(hdtv|pdtv)\b`), SourceHDTV},
+}
+
+// Source returns the source of the release parsed from the fields selected by SetParseSource.
+// With ParseBestOfBoth the higher ranked source wins.
+func (t Torrent) Source() Source {
+	source, _ := parseTorrent(t, func(s string) (Source, bool) {
+		for _, p := range sourcePatterns {
+			if p.re.MatchString(s) {
+				return p.source, true
+			}
+		}
+		return SourceUnknown, false
+	}, func(a, b Source) bool { return SourceRank(a) > SourceRank(b) })
+	return source
+}
+
+// SourceRank returns the default rank of the source, higher being better:
+// BluRay > WEB-DL > WEBRip > HDTV > Unknown.
+func SourceRank(src Source) int {
+	switch src {
+	case SourceBluRay:
+		return 4
+	case SourceWEBDL:
+		return 3
+	case SourceWEBRip:
+		return 2
+	case SourceHDTV:
+		return 1
+	}
+	return 0
+}
+
+// Resolution is the vertical video resolution of a release.
+type Resolution int
+
+// Resolutions are ordered from the lowest to the highest.
+const (
+	ResUnknown Resolution = iota
+	Res480p
+	Res720p
+	Res1080p
+	Res2160p
+)
+
+func (r Resolution) String() string {
+	switch r {
+	case Res480p:
+		return "480p"
+	case Res720p:
+		return "720p"
+	case Res1080p:
+		return "1080p"
+	case Res2160p:
+		return "2160p"
+	}
+	return "Unknown"
+}
+
+// resolutionPatterns map the markers found in release names to their Resolution, checked in order.
+// Explicit resolutions come first, so that codecs such as x264 are never mistaken for one.
+// A release marked HDTV only is the standard definition capture scene releases default to.
+var resolutionPatterns = []struct {
+	re         *regexp.Regexp
+	resolution Resolution
+}{
+	{regexp.MustCompile(`(?i)\b2160[pi]\b`), Res2160p},
+	{regexp.MustCompile(`(?i)\b1080[pi]\b`), Res1080p},
+	{regexp.MustCompile(`(?i)\b720[pi]\b`), Res720p},
+	{regexp.MustCompile(`(?i)\b480[pi]\b`), Res480p},
+	{regexp.MustCompile(`(?i)\b(4k|uhd)\b`), Res2160p},
+	{regexp.MustCompile(`(?i)\b(hdtv|pdtv)\b`), Res480p},
+}
+
+// Resolution returns the resolution of the release parsed from the fields selected by SetParseSource.
+// With ParseBestOfBoth the higher resolution wins.
+func (t Torrent) Resolution() Resolution {
+	resolution, _ := parseTorrent(t, func(s string) (Resolution, bool) {
+		for _, p := range resolutionPatterns {
+			if p.re.MatchString(s) {
+				return p.resolution, true
+			}
+		}
+		return ResUnknown, false
+	}, func(a, b Resolution) bool { return a > b })
+	return resolution
+}
+
+// QualityPrefs tell which of two releases of an episode is the better one.
+//
+// By default a higher resolution always wins, and the source (see SourceRank) decides
+// between releases of the same resolution.
+type QualityPrefs struct {
+	// MaxResolution is the highest resolution worth upgrading to, e.g. to stay at 1080p.
+	// Releases above it are worse than every release within it. Zero allows any resolution.
+	MaxResolution Resolution
+	// SourceFirst ranks releases by their source before their resolution, e.g. to prefer
+	// a 720p BluRay over a 1080p HDTV capture.
+	SourceFirst bool
+	// Groups are release groups (see Torrent.ReleaseGroup) that win over all others
+	// when both the resolution and the source are the same. Groups are matched case-insensitively.
+	Groups []string
+}
+
+// compare returns -1 if the release a is worse than b, 1 if it is better
+// and 0 if they are of the same quality.
+func (p QualityPrefs) compare(a, b Torrent) int {
+	resA, resB := a.Resolution(), b.Resolution()
+	if p.MaxResolution != ResUnknown {
+		if c := cmp.Compare(boolRank(resA <= p.MaxResolution), boolRank(resB <= p.MaxResolution)); c != 0 {
+			return c
+		}
+	}
+
+	byResolution := cmp.Compare(resA, resB)
+	bySource := cmp.Compare(SourceRank(a.Source()), SourceRank(b.Source()))
+	byGroup := cmp.Compare(boolRank(p.preferred(a)), boolRank(p.preferred(b)))
+	if p.SourceFirst {
+		return cmp.Or(bySource, byResolution, byGroup)
+	}
+	return cmp.Or(byResolution, bySource, byGroup)
+}
+
+// preferred reports whether the release is from one of the preferred Groups.
+func (p QualityPrefs) preferred(t Torrent) bool {
+	group := t.ReleaseGroup()
+	return group != "" && slices.ContainsFunc(p.Groups, func(g string) bool { return strings.EqualFold(g, group) })
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// IsUpgrade reports whether the torrent is a strictly better release than current by prefs,
+// and within prefs.MaxResolution. It only compares the quality of the releases,
+// it does not check that both are of the same episode.
+func (t Torrent) IsUpgrade(current Torrent, prefs QualityPrefs) bool {
+	if prefs.MaxResolution != ResUnknown && t.Resolution() > prefs.MaxResolution {
+		return false
+	}
+	return prefs.compare(t, current) > 0
+}
+
+// FindUpgrades returns, for every episode covered by the torrents in have, the best torrent
+// in available that is an upgrade (see Torrent.IsUpgrade) of the best one had. Episodes without
+// any upgrade are absent. Episodes are matched the way FindEpisodes does, so a multi-episode
+// torrent counts for every episode it covers. Between upgrades of the same quality, the one
+// with the most seeds and then the newest one wins.
+func FindUpgrades(have []Torrent, available []Torrent, prefs QualityPrefs) map[SeasonEpisode]Torrent {
+	best := make(map[SeasonEpisode]Torrent)
+	for _, torrent := range have {
+		for _, ep := range torrent.episodes() {
+			if current, ok := best[ep]; !ok || prefs.compare(torrent, current) > 0 {
+				best[ep] = torrent
+			}
+		}
+	}
+
+	upgrades := make(map[SeasonEpisode]Torrent)
+	for _, torrent := range available {
+		for _, ep := range torrent.episodes() {
+			current, ok := best[ep]
+			if !ok || !torrent.IsUpgrade(current, prefs) {
+				continue
+			}
+			found, ok := upgrades[ep]
+			if !ok {
+				upgrades[ep] = torrent
+				continue
+			}
+			c := prefs.compare(torrent, found)
+			if c == 0 {
+				c = torrent.CompareTo(found, SortKeySeeds, SortKeyDate)
+			}
+			if c > 0 {
+				upgrades[ep] = torrent
+			}
+		}
+	}
+	return upgrades
+}
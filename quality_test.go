@@ -0,0 +1,153 @@
+package eztv
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestTorrentSource(t *testing.T) {
+	tests := []struct {
+		title string
+		want  Source
+	}{
+		{"Show.S01E01.1080p.BluRay.x264-GRP", SourceBluRay},
+		{"Show.S01E01.720p.BDRip.x264-GRP", SourceBluRay},
+		{"Show.S01E01.1080p.WEB-DL.DDP5.1.H.264-GRP", SourceWEBDL},
+		{"Show.S01E01.1080p.WEB.h264-GRP", SourceWEBDL},
+		{"Show S01E01 720p WEBRip x264", SourceWEBRip},
+		{"Show.S01E01.HDTV.x264-GRP", SourceHDTV},
+		{"Show.S01E01.720p.x264-GRP", SourceUnknown},
+	}
+	for _, tt := range tests {
+		if got := (Torrent{Title: tt.title}).Source(); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSourceRank(t *testing.T) {
+	torrents := []Torrent{
+		{ID: 1, Title: "Show.S01E01.HDTV.x264-GRP"},
+		{ID: 2, Title: "Show.S01E01.720p.x264-GRP"},
+		{ID: 3, Title: "Show.S01E01.1080p.BluRay.x264-GRP"},
+		{ID: 4, Title: "Show.S01E01.720p.WEBRip.x264-GRP"},
+		{ID: 5, Title: "Show.S01E01.1080p.WEB-DL.H.264-GRP"},
+		{ID: 6, Title: "Show.S01E01.720p.HDTV.x264-OTHER"},
+	}
+	slices.SortStableFunc(torrents, func(a, b Torrent) int {
+		return cmp.Compare(SourceRank(b.Source()), SourceRank(a.Source()))
+	})
+	if got := ids(torrents); !slices.Equal(got, []int{3, 5, 4, 1, 6, 2}) {
+		t.Fatalf("got %v, want BluRay, WEB-DL, WEBRip, HDTV and then unknown sources [3 5 4 1 6 2]", got)
+	}
+}
+
+func TestTorrentResolution(t *testing.T) {
+	tests := []struct {
+		torrent Torrent
+		want    Resolution
+	}{
+		{Torrent{Title: "Show S01E01 720p HDTV x264-GRP"}, Res720p},
+		{Torrent{Title: "Show.S01E01.1080P.WEB.H264-GRP"}, Res1080p},
+		{Torrent{Title: "Show.S01E01.2160p.WEB.x265-GRP"}, Res2160p},
+		{Torrent{Title: "Show.S01E01.1080i.HDTV.MPA2.0.H.264-GRP"}, Res1080p},
+		{Torrent{Title: "Show S01E01 4K HDR"}, Res2160p},
+		{Torrent{Title: "Show.S01E01.uhd.bluray.x265-GRP"}, Res2160p},
+		{Torrent{Title: "Show.S01E01.hdtv.x264-GRP"}, Res480p},
+		{Torrent{Title: "Show.S01E01.480p.x264-GRP"}, Res480p},
+		// Codecs, years and episode numbers are no resolution.
+		{Torrent{Title: "Show.S01E01.x264-GRP"}, ResUnknown},
+		{Torrent{Title: "Show.S01E01.WEB.X265-GRP"}, ResUnknown},
+		{Torrent{Title: "Show 2019 S07E20 1080 Episodes"}, ResUnknown},
+		{Torrent{Title: "Show.720.S01E01.480p.x264-GRP"}, Res480p},
+		{Torrent{Title: "Show.S01E01.WEB", Filename: "Show.S01E01.720p.WEB.mkv"}, Res720p},
+		{Torrent{}, ResUnknown},
+	}
+	for _, tt := range tests {
+		if got := tt.torrent.Resolution(); got != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt.torrent, got, tt.want)
+		}
+	}
+}
+
+func TestResolutionString(t *testing.T) {
+	for r, want := range map[Resolution]string{
+		ResUnknown: "Unknown",
+		Res480p:    "480p",
+		Res720p:    "720p",
+		Res1080p:   "1080p",
+		Res2160p:   "2160p",
+		99:         "Unknown",
+	} {
+		if got := r.String(); got != want {
+			t.Errorf("%d: got %q, want %q", r, got, want)
+		}
+	}
+}
+
+func TestIsUpgrade(t *testing.T) {
+	tests := []struct {
+		torrent, current string
+		prefs            QualityPrefs
+		want             bool
+	}{
+		{"Show.S01E01.1080p.HDTV.x264-GRP", "Show.S01E01.720p.BluRay.x264-GRP", QualityPrefs{}, true},
+		{"Show.S01E01.720p.BluRay.x264-GRP", "Show.S01E01.720p.WEB-DL.x264-GRP", QualityPrefs{}, true},
+		{"Show.S01E01.720p.WEB-DL.x264-GRP", "Show.S01E01.720p.BluRay.x264-GRP", QualityPrefs{}, false},
+		// The same quality is no upgrade.
+		{"Show.S01E01.720p.WEB-DL.x264-GRP", "Show.S01E01.720p.WEB-DL.x264-OTHER", QualityPrefs{}, false},
+		{"Show.S01E01.720p.BluRay.x264-GRP", "Show.S01E01.1080p.HDTV.x264-GRP", QualityPrefs{SourceFirst: true}, true},
+		{"Show.S01E01.1080p.HDTV.x264-GRP", "Show.S01E01.720p.BluRay.x264-GRP", QualityPrefs{SourceFirst: true}, false},
+		{"Show.S01E01.2160p.WEB-DL.x265-GRP", "Show.S01E01.720p.WEB-DL.x264-GRP", QualityPrefs{MaxResolution: Res1080p}, false},
+		{"Show.S01E01.1080p.WEB-DL.x264-GRP", "Show.S01E01.2160p.WEB-DL.x265-GRP", QualityPrefs{MaxResolution: Res1080p}, true},
+		{"Show.S01E01.720p.WEB-DL.x264-grp", "Show.S01E01.720p.WEB-DL.x264-OTHER", QualityPrefs{Groups: []string{"GRP"}}, true},
+		{"Show.S01E01.720p.WEB-DL.x264-OTHER", "Show.S01E01.720p.WEB-DL.x264-GRP", QualityPrefs{Groups: []string{"GRP"}}, false},
+		{"Show.S01E01.720p.WEB-DL.x264-GRP", "Show.S01E01.1080p.WEB-DL.x264-OTHER", QualityPrefs{Groups: []string{"GRP"}}, false},
+	}
+	for _, tt := range tests {
+		got := Torrent{Title: tt.torrent}.IsUpgrade(Torrent{Title: tt.current}, tt.prefs)
+		if got != tt.want {
+			t.Errorf("%q over %q with %+v: got %t, want %t", tt.torrent, tt.current, tt.prefs, got, tt.want)
+		}
+	}
+}
+
+func TestFindUpgrades(t *testing.T) {
+	have := []Torrent{
+		{ID: 1, Title: "Show.S01E01.720p.HDTV.x264-GRP", Season: "1", Episode: "1"},
+		// The best release had of an episode is the one upgraded.
+		{ID: 2, Title: "Show.S01E02.480p.HDTV.x264-GRP", Season: "1", Episode: "2"},
+		{ID: 3, Title: "Show.S01E02.1080p.WEB-DL.x264-GRP", Season: "1", Episode: "2"},
+		{ID: 4, Title: "Show.S01E03E04.720p.WEB-DL.x264-GRP", Season: "1", Episode: "3"},
+		{ID: 5, Title: "Show.S02E01.720p.WEB-DL.x264-GRP", Season: "2", Episode: "1"},
+	}
+	available := []Torrent{
+		{ID: 11, Title: "Show.S01E01.1080p.HDTV.x264-GRP", Season: "1", Episode: "1", Seeds: 5},
+		{ID: 12, Title: "Show.S01E01.1080p.HDTV.x264-OTHER", Season: "1", Episode: "1", Seeds: 50},
+		{ID: 13, Title: "Show.S01E01.720p.BluRay.x264-GRP", Season: "1", Episode: "1", Seeds: 500},
+		// Equal or lower quality is no upgrade.
+		{ID: 14, Title: "Show.S01E02.1080p.WEB-DL.x264-OTHER", Season: "1", Episode: "2"},
+		{ID: 15, Title: "Show.S01E02.720p.BluRay.x264-GRP", Season: "1", Episode: "2"},
+		// A single episode upgrades one episode of a multi-episode torrent.
+		{ID: 16, Title: "Show.S01E04.1080p.WEB-DL.x264-GRP", Season: "1", Episode: "4"},
+		// Episodes are matched by season too.
+		{ID: 17, Title: "Show.S01E05.2160p.WEB-DL.x265-GRP", Season: "1", Episode: "5"},
+		{ID: 18, Title: "Show.S03E01.2160p.WEB-DL.x265-GRP", Season: "3", Episode: "1"},
+	}
+
+	got := make(map[SeasonEpisode]int)
+	for ep, torrent := range FindUpgrades(have, available, QualityPrefs{}) {
+		got[ep] = torrent.ID
+	}
+	// S02E01 has nothing available.
+	want := map[SeasonEpisode]int{{1, 1}: 12, {1, 4}: 16}
+	if !maps.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if upgrades := FindUpgrades(have, nil, QualityPrefs{}); len(upgrades) != 0 {
+		t.Fatalf("got upgrades %v with nothing available", upgrades)
+	}
+}
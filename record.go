@@ -0,0 +1,106 @@
+package eztv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// recording is a recorded API response as it is stored on disk.
+type recording struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// recordingPath returns the file the response for the URL is recorded into.
+func recordingPath(dir, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// recorder is an http.RoundTripper that writes every response it receives into dir.
+type recorder struct {
+	next http.RoundTripper
+	dir  string
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	b, err := json.MarshalIndent(recording{
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(body),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(recordingPath(r.dir, req.URL.String()), b, 0o644); err != nil {
+		return nil, fmt.Errorf("record response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// replayer is an http.RoundTripper that serves responses recorded into dir instead of using the network.
+type replayer struct {
+	dir string
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	b, err := os.ReadFile(recordingPath(r.dir, req.URL.String()))
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s: %w", req.URL, err)
+	}
+
+	var rec recording
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, fmt.Errorf("decode recorded response for %s: %w", req.URL, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.Body))),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+// withTransport returns a copy of client that sends requests through the transport returned by wrap.
+func withTransport(client *http.Client, wrap func(next http.RoundTripper) http.RoundTripper) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	wrapped := *client
+	wrapped.Transport = wrap(next)
+	return &wrapped
+}
@@ -0,0 +1,52 @@
+package eztv
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	var served atomic.Int32
+	api := newFakeAPI(3)
+	c := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served.Add(1)
+		w.Header().Set("Server", "fake-eztv")
+		api.ServeHTTP(w, r)
+	}), WithRecorder(dir))
+
+	urlOptions := URLOptions{ImdbID: "tt1", Page: 1, Limit: 2}
+	recorded, recordedMeta, err := c.GetTorrentsWithResponse(context.Background(), urlOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("recorded %d files, want 1", len(entries))
+	}
+
+	// The replaying client has the same base URL, but its server is never reached.
+	replaying := New(WithBaseURL(c.mirrors[0]), WithReplayer(dir))
+	for range 2 {
+		page, meta, err := replaying.GetTorrentsWithResponse(context.Background(), urlOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(page.Torrents, recorded.Torrents) || page.TorrentsCount != recorded.TorrentsCount {
+			t.Fatalf("replayed %+v, want %+v", page, recorded)
+		}
+		if meta.StatusCode != recordedMeta.StatusCode || meta.Header.Get("Server") != "fake-eztv" {
+			t.Fatalf("replayed status %d and headers %v, want the recorded ones", meta.StatusCode, meta.Header)
+		}
+	}
+	if n := served.Load(); n != 1 {
+		t.Fatalf("server was reached %d times, want only while recording", n)
+	}
+
+	if _, err := replaying.GetTorrents(context.Background(), URLOptions{ImdbID: "tt1", Page: 2, Limit: 2}); err == nil {
+		t.Fatal("replaying a request that was never recorded did not fail")
+	}
+}
@@ -0,0 +1,124 @@
+package eztv
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// ShowReport summarizes the torrents available for a show.
+type ShowReport struct {
+	ImdbID string
+	// TotalTorrents is the number of torrents of the show.
+	TotalTorrents int
+	// TotalEpisodes is the number of distinct episodes covered by the torrents.
+	TotalEpisodes int
+	// Seasons are the season numbers that have any torrents, in ascending order.
+	Seasons []int
+	// LatestEpisode is the highest episode of the highest season with episode torrents.
+	// It is zero if the show only has season packs.
+	LatestEpisode SeasonEpisode
+	// PerSeason breaks the report down by season number.
+	PerSeason map[int]SeasonReport
+}
+
+// SeasonReport summarizes the torrents available for a single season of a show.
+type SeasonReport struct {
+	// Episodes are the episode numbers that have torrents, in ascending order.
+	Episodes []int
+	// Torrents is the number of torrents of the season, including packs.
+	Torrents int
+	// Packs is the number of torrents that cover the whole season rather than single episodes.
+	Packs int
+}
+
+// MissingEpisodes returns the episode numbers of the season, from 1 up to the highest one with torrents,
+// that have no torrents, in ascending order.
+func (sr SeasonReport) MissingEpisodes() []int {
+	var missing []int
+	next := 1
+	for _, ep := range sr.Episodes {
+		for ; next < ep; next++ {
+			missing = append(missing, next)
+		}
+		next = max(next, ep+1)
+	}
+	return missing
+}
+
+// ShowReport fetches every torrent of the show and summarizes them into a ShowReport.
+// Torrents without season data are only counted in TotalTorrents.
+func (c *Client) ShowReport(ctx context.Context, imdbID string) (*ShowReport, error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return nil, ErrMissingImdbID
+	}
+
+	report := &ShowReport{
+		ImdbID:    imdbID,
+		PerSeason: make(map[int]SeasonReport),
+	}
+	episodes := make(map[SeasonEpisode]struct{})
+	err := c.forEachPage(ctx, imdbID, 0, func(page *Page) bool {
+		for _, torrent := range page.Torrents {
+			report.TotalTorrents++
+
+			season, ok := torrent.SeasonNumber()
+			if !ok {
+				continue
+			}
+			sr := report.PerSeason[season]
+			sr.Torrents++
+
+			covered := slices.DeleteFunc(torrent.episodes(), func(ep SeasonEpisode) bool { return ep.Episode == 0 })
+			if len(covered) == 0 {
+				sr.Packs++
+			}
+			for _, ep := range covered {
+				if _, ok := episodes[ep]; !ok {
+					episodes[ep] = struct{}{}
+					sr.Episodes = append(sr.Episodes, ep.Episode)
+				}
+			}
+			report.PerSeason[season] = sr
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.TotalEpisodes = len(episodes)
+	for season, sr := range report.PerSeason {
+		slices.Sort(sr.Episodes)
+		report.Seasons = append(report.Seasons, season)
+	}
+	slices.Sort(report.Seasons)
+
+	for ep := range episodes {
+		if ep.Season > report.LatestEpisode.Season ||
+			(ep.Season == report.LatestEpisode.Season && ep.Episode > report.LatestEpisode.Episode) {
+			report.LatestEpisode = ep
+		}
+	}
+
+	return report, nil
+}
+
+// MissingEpisodes fetches every torrent of the show and returns, per season number, the episodes
+// without any torrents (see SeasonReport.MissingEpisodes). The last episode of each season is
+// inferred from the highest one that has torrents. Seasons without gaps are left out.
+func (c *Client) MissingEpisodes(ctx context.Context, imdbID string) (map[int][]int, error) {
+	report, err := c.ShowReport(ctx, imdbID)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make(map[int][]int)
+	for season, sr := range report.PerSeason {
+		if eps := sr.MissingEpisodes(); len(eps) > 0 {
+			missing[season] = eps
+		}
+	}
+	return missing, nil
+}
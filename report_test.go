@@ -0,0 +1,89 @@
+package eztv
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// showFixture is a show with two seasons and a season pack, served as imdb_id 1,
+// and a show with only season packs, served as imdb_id 2.
+var showFixture = []Torrent{
+	{ID: 12, ImdbID: "tt2", Title: "Other.S02.1080p", Season: "2"},
+	{ID: 11, ImdbID: "tt2", Title: "Other.S01.1080p", Season: "1"},
+	{ID: 10, ImdbID: "tt1", Title: "Show.S02E05.720p", Season: "2", Episode: "5"},
+	{ID: 9, ImdbID: "tt1", Title: "Show.S02E02E03.1080p", Season: "2", Episode: "2"},
+	{ID: 8, ImdbID: "tt1", Title: "Show.S02E01.1080p", Season: "2", Episode: "1"},
+	{ID: 7, ImdbID: "tt1", Title: "Show.S02E01.720p", Season: "2", Episode: "1"},
+	{ID: 6, ImdbID: "tt1", Title: "Show.S01.1080p", Season: "1"},
+	{ID: 5, ImdbID: "tt1", Title: "Show.S01E06.720p", Season: "1", Episode: "6"},
+	{ID: 4, ImdbID: "tt1", Title: "Show.S01E04.720p", Season: "1", Episode: "4"},
+	{ID: 3, ImdbID: "tt1", Title: "Show.S01E02.720p", Season: "1", Episode: "2"},
+	{ID: 2, ImdbID: "tt1", Title: "Show.S01E01.720p", Season: "1", Episode: "1"},
+	{ID: 1, ImdbID: "tt1", Title: "Show.Special.720p"},
+}
+
+func TestShowReport(t *testing.T) {
+	c := testClient(t, &fakeAPI{torrents: showFixture, maxLimit: MaxEZTVAPILimit})
+
+	report, err := c.ShowReport(context.Background(), "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ShowReport{
+		ImdbID:        "1",
+		TotalTorrents: 10,
+		TotalEpisodes: 8,
+		Seasons:       []int{1, 2},
+		LatestEpisode: SeasonEpisode{Season: 2, Episode: 5},
+		PerSeason: map[int]SeasonReport{
+			1: {Episodes: []int{1, 2, 4, 6}, Torrents: 5, Packs: 1},
+			2: {Episodes: []int{1, 2, 3, 5}, Torrents: 4},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("got %+v, want %+v", report, want)
+	}
+
+	report, err = c.ShowReport(context.Background(), "tt2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &ShowReport{
+		ImdbID:        "2",
+		TotalTorrents: 2,
+		Seasons:       []int{1, 2},
+		PerSeason: map[int]SeasonReport{
+			1: {Torrents: 1, Packs: 1},
+			2: {Torrents: 1, Packs: 1},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("got %+v for a show with only packs, want %+v", report, want)
+	}
+}
+
+func TestMissingEpisodes(t *testing.T) {
+	c := testClient(t, &fakeAPI{torrents: showFixture, maxLimit: MaxEZTVAPILimit})
+
+	missing, err := c.MissingEpisodes(context.Background(), "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int][]int{1: {3, 5}, 2: {4}}; !reflect.DeepEqual(missing, want) {
+		t.Fatalf("got %v, want %v", missing, want)
+	}
+
+	// Seasons without any episodes cannot have gaps.
+	missing, err = c.MissingEpisodes(context.Background(), "tt2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("got %v for a show with only packs, want no gaps", missing)
+	}
+
+	if _, err := c.MissingEpisodes(context.Background(), ""); err == nil {
+		t.Fatal("reported a show without an IMDb ID")
+	}
+}
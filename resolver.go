@@ -0,0 +1,60 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrMissingResolver = errors.New("missing imdb resolver")
+
+// IMDbResolver resolves a show title into its IMDb ID, e.g. by querying OMDb.
+//
+// The package does not ship an implementation, it has to be provided with WithIMDbResolver.
+type IMDbResolver interface {
+	Resolve(ctx context.Context, title string) (string, error)
+}
+
+// SearchByTitle returns all torrents of the show with the given title.
+//
+// The title is resolved into an IMDb ID with the IMDbResolver set by WithIMDbResolver.
+// If no resolver is configured, it returns ErrMissingResolver.
+//
+// If the search was cut short by WithMaxSearchPages, the torrents found so far are returned
+// together with ErrSearchTruncated.
+func (c *Client) SearchByTitle(ctx context.Context, title string) ([]Torrent, error) {
+	imdbID, err := c.resolveTitle(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+
+	var torrents []Torrent
+	err = c.forEachPage(ctx, imdbID, c.maxSearchPages, func(page *Page) bool {
+		torrents = append(torrents, page.Torrents...)
+		return true
+	})
+	if err != nil && !errors.Is(err, ErrSearchTruncated) {
+		return nil, err
+	}
+
+	return torrents, err
+}
+
+func (c *Client) resolveTitle(ctx context.Context, title string) (string, error) {
+	if c.resolver == nil {
+		return "", ErrMissingResolver
+	}
+
+	imdbID, err := c.resolver.Resolve(ctx, title)
+	if err != nil {
+		return "", fmt.Errorf("resolve %q: %w", title, err)
+	}
+
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return "", ErrMissingImdbID
+	}
+
+	return imdbID, nil
+}
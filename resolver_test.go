@@ -0,0 +1,69 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// stubResolver resolves the titles it maps, failing for any other.
+type stubResolver map[string]string
+
+func (r stubResolver) Resolve(_ context.Context, title string) (string, error) {
+	imdbID, ok := r[title]
+	if !ok {
+		return "", errors.New("unknown title")
+	}
+	return imdbID, nil
+}
+
+func TestSearchByTitle(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit, torrents: []Torrent{
+		{ID: 3, ImdbID: "tt2"},
+		{ID: 2, ImdbID: "tt1"},
+		{ID: 1, ImdbID: "tt1"},
+	}}
+	c := testClient(t, api, WithIMDbResolver(stubResolver{"The Show": "tt1"}))
+
+	torrents, err := c.SearchByTitle(context.Background(), "The Show")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(torrents); !slices.Equal(got, []int{2, 1}) {
+		t.Fatalf("got torrents %v, want [2 1]", got)
+	}
+	if imdbID := api.requests()[0].Get("imdb_id"); imdbID != "1" {
+		t.Fatalf("requested imdb_id %q, want 1", imdbID)
+	}
+
+	if _, err := c.SearchByTitle(context.Background(), "Another Show"); err == nil {
+		t.Fatal("resolving an unknown title did not fail")
+	}
+	if _, err := testClient(t, api).SearchByTitle(context.Background(), "The Show"); !errors.Is(err, ErrMissingResolver) {
+		t.Fatalf("got %v, want ErrMissingResolver", err)
+	}
+}
+
+func TestWithMaxSearchPages(t *testing.T) {
+	api := newFakeAPI(350)
+	c := testClient(t, api, WithIMDbResolver(stubResolver{"The Show": "tt1"}), WithMaxSearchPages(2))
+
+	torrents, err := c.SearchByTitle(context.Background(), "The Show")
+	if !errors.Is(err, ErrSearchTruncated) {
+		t.Fatalf("got %v, want ErrSearchTruncated", err)
+	}
+	if got := ids(torrents); !slices.Equal(got, ids(torrentsWithIDs(151, 350))) {
+		t.Fatalf("got %d torrents, want the 200 of the first two pages", len(got))
+	}
+	if n := len(api.requests()); n != 2 {
+		t.Fatalf("made %d requests, want 2", n)
+	}
+
+	// A show within the page cap is not truncated.
+	torrents, err = testClient(t, newFakeAPI(150), WithIMDbResolver(stubResolver{"The Show": "tt1"}), WithMaxSearchPages(2)).
+		SearchByTitle(context.Background(), "The Show")
+	if err != nil || len(torrents) != 150 {
+		t.Fatalf("got %d torrents, %v, want all 150", len(torrents), err)
+	}
+}
@@ -0,0 +1,80 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryPolicy is the retry configuration set with WithRetry.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+// shouldRetry reports whether a request that failed with err may succeed when re-issued:
+// network errors and 5xx or 429 responses are retried, unless ctx is already done.
+func shouldRetry(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// delay returns how long to wait before the given retry, starting at 1.
+// It is drawn at random between zero and baseDelay doubled for every retry made before.
+// A valid Retry-After header of a 429 response takes precedence.
+func (p retryPolicy) delay(retry int, err error, header http.Header) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+		if d, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+
+	if p.baseDelay <= 0 {
+		return 0
+	}
+	backoff := p.baseDelay << min(retry-1, 30)
+	if backoff <= 0 { // Overflow.
+		backoff = p.baseDelay
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// parseRetryAfter parses a Retry-After header value given either in seconds or as an HTTP date.
+func parseRetryAfter(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(s); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(s); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d to pass, returning ctx.Err() if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
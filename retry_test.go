@@ -0,0 +1,163 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyAPI fails the first failures requests with status, and serves api afterwards.
+func flakyAPI(api http.Handler, failures int32, status int, header http.Header) (http.Handler, *atomic.Int32) {
+	var requests atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			http.Error(w, "flaky", status)
+			return
+		}
+		api.ServeHTTP(w, r)
+	}), &requests
+}
+
+func TestShouldRetry(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"no error", context.Background(), nil, false},
+		{"500", context.Background(), &APIError{StatusCode: http.StatusInternalServerError}, true},
+		{"503 wrapped", context.Background(), fmt.Errorf("mirror: %w", &APIError{StatusCode: http.StatusServiceUnavailable}), true},
+		{"429", context.Background(), &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"404", context.Background(), &APIError{StatusCode: http.StatusNotFound}, false},
+		{"network", context.Background(), &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, true},
+		{"decode", context.Background(), errors.New("invalid character"), false},
+		{"context done", cancelled, &APIError{StatusCode: http.StatusBadGateway}, false},
+	}
+	for _, tt := range tests {
+		if got := shouldRetry(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	for _, status := range []int{http.StatusBadGateway, http.StatusTooManyRequests} {
+		api, requests := flakyAPI(newFakeAPI(3), 2, status, nil)
+		page, err := testClient(t, api, WithRetry(3, time.Millisecond)).GetTorrents(context.Background(), URLOptions{Page: 1})
+		if err != nil {
+			t.Fatalf("%d: %v", status, err)
+		}
+		if len(page.Torrents) != 3 || requests.Load() != 3 {
+			t.Fatalf("%d: got %d torrents after %d requests, want 3 after 3", status, len(page.Torrents), requests.Load())
+		}
+	}
+
+	// Errors that a retry cannot fix are returned right away.
+	api, requests := flakyAPI(newFakeAPI(3), 2, http.StatusNotFound, nil)
+	if _, err := testClient(t, api, WithRetry(3, time.Millisecond)).GetTorrents(context.Background(), URLOptions{Page: 1}); err == nil || requests.Load() != 1 {
+		t.Fatalf("got %v after %d requests, want the 404 after 1", err, requests.Load())
+	}
+
+	// Without WithRetry, requests are not retried.
+	api, requests = flakyAPI(newFakeAPI(3), 1, http.StatusBadGateway, nil)
+	if _, err := testClient(t, api).GetTorrents(context.Background(), URLOptions{Page: 1}); err == nil || requests.Load() != 1 {
+		t.Fatalf("got %v after %d requests, want the 502 after 1", err, requests.Load())
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	// The base delay would make the test time out, if Retry-After was not honoured.
+	api, _ := flakyAPI(newFakeAPI(3), 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	c := testClient(t, api, WithRetry(2, time.Hour))
+
+	start := time.Now()
+	if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 3*time.Second {
+		t.Fatalf("retried after %s, want the 1s of Retry-After", elapsed)
+	}
+}
+
+func TestWithRetryCancel(t *testing.T) {
+	api, requests := flakyAPI(newFakeAPI(3), 5, http.StatusBadGateway, nil)
+	c := testClient(t, api, WithRetry(5, time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.GetTorrents(ctx, URLOptions{Page: 1}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the context deadline while backing off", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || requests.Load() > 2 {
+		t.Fatalf("returned after %s and %d requests, want prompt cancellation of the back-off", elapsed, requests.Load())
+	}
+}
+
+func TestRetryExhaustedError(t *testing.T) {
+	statuses := []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}
+	var requests atomic.Int32
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[min(int(requests.Add(1)), len(statuses))-1]
+		http.Error(w, http.StatusText(status), status)
+	})
+
+	_, err := testClient(t, failing, WithRetry(3, time.Millisecond)).GetTorrents(context.Background(), URLOptions{Page: 1})
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("got %v, want a *RetryExhaustedError", err)
+	}
+	if exhausted.Attempts != 3 || requests.Load() != 3 {
+		t.Fatalf("reported %d attempts after %d requests, want 3", exhausted.Attempts, requests.Load())
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got %v, want it to unwrap to the 503 of the last attempt", err)
+	}
+	if want := "giving up after 3 attempts: "; !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("got %q, want it prefixed with %q", err, want)
+	}
+
+	// Errors that are not retried, and failures without WithRetry, are returned as they are.
+	for _, c := range []*Client{testClient(t, failing), testClient(t, http.NotFoundHandler(), WithRetry(3, time.Millisecond))} {
+		if _, err := c.GetTorrents(context.Background(), URLOptions{Page: 1}); errors.As(err, &exhausted) {
+			t.Fatalf("got %v, want the error of the only attempt", err)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		value  string
+		min    time.Duration
+		max    time.Duration
+		wantOK bool
+	}{
+		{"", 0, 0, false},
+		{"30", 30 * time.Second, 30 * time.Second, true},
+		{"-5", 0, 0, true},
+		{future, 58 * time.Second, time.Minute, true},
+		{"Mon, 01 Jan 2001 00:00:00 GMT", 0, 0, true},
+		{"soon", 0, 0, false},
+	}
+	for _, tt := range tests {
+		d, ok := parseRetryAfter(tt.value)
+		if ok != tt.wantOK || d < tt.min || d > tt.max {
+			t.Errorf("%q: got %s, %t, want between %s and %s, %t", tt.value, d, ok, tt.min, tt.max, tt.wantOK)
+		}
+	}
+}
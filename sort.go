@@ -0,0 +1,134 @@
+package eztv
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// SortKey is a Torrent field that torrents can be compared by.
+type SortKey int
+
+const (
+	SortKeyID SortKey = iota
+	SortKeySeason
+	SortKeyEpisode
+	SortKeySeeds
+	SortKeyPeers
+	SortKeyDate
+	SortKeySize
+)
+
+// SortBy is the order URLOptions.Sort puts the torrents of a page in.
+type SortBy int
+
+const (
+	// SortNone keeps the order of the API.
+	SortNone SortBy = iota
+	// SortSeedsDesc sorts by seeds, most first.
+	SortSeedsDesc
+	// SortDateDesc sorts by release date, newest first.
+	SortDateDesc
+	// SortSizeDesc sorts by size, largest first. Torrents with a malformed size count as 0 bytes.
+	SortSizeDesc
+)
+
+// sortTorrents stable sorts the torrents by the given order, so that ties keep the order of the API.
+func sortTorrents(torrents []Torrent, by SortBy) {
+	var key func(t Torrent) int64
+	switch by {
+	case SortSeedsDesc:
+		key = func(t Torrent) int64 { return int64(t.Seeds) }
+	case SortDateDesc:
+		key = func(t Torrent) int64 { return int64(t.DateReleasedUnix) }
+	case SortSizeDesc:
+		key = func(t Torrent) int64 {
+			size, _ := t.Size()
+			return size
+		}
+	default:
+		return
+	}
+
+	slices.SortStableFunc(torrents, func(a, b Torrent) int {
+		return cmp.Compare(key(b), key(a))
+	})
+}
+
+// CompareTo compares the torrent with other by each of the keys in order, falling through
+// to the next key on ties. It returns -1 if t sorts before other, 1 if it sorts after
+// and 0 if they are equal for every key. Values are compared in ascending order.
+//
+// Season, episode and size are parsed from their string fields. Values that fail to parse
+// always sort before the ones that were parsed successfully, so the result is deterministic.
+func (t Torrent) CompareTo(other Torrent, keys ...SortKey) int {
+	for _, key := range keys {
+		var c int
+		switch key {
+		case SortKeyID:
+			c = cmp.Compare(t.ID, other.ID)
+		case SortKeySeason:
+			a, aOK := t.SeasonNumber()
+			b, bOK := other.SeasonNumber()
+			c = compareParsed(a, aOK, b, bOK)
+		case SortKeyEpisode:
+			a, aOK := t.EpisodeNumber()
+			b, bOK := other.EpisodeNumber()
+			c = compareParsed(a, aOK, b, bOK)
+		case SortKeySeeds:
+			c = cmp.Compare(t.Seeds, other.Seeds)
+		case SortKeyPeers:
+			c = cmp.Compare(t.Peers, other.Peers)
+		case SortKeyDate:
+			c = cmp.Compare(t.DateReleasedUnix, other.DateReleasedUnix)
+		case SortKeySize:
+			a, aErr := t.Size()
+			b, bErr := other.Size()
+			c = compareParsed(a, aErr == nil, b, bErr == nil)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// compareParsed compares two optionally parsed values, ordering unparsed values first.
+func compareParsed[T cmp.Ordered](a T, aOK bool, b T, bOK bool) int {
+	switch {
+	case !aOK && !bOK:
+		return 0
+	case !aOK:
+		return -1
+	case !bOK:
+		return 1
+	}
+	return cmp.Compare(a, b)
+}
+
+// PreferGroups returns the torrents stable sorted so that the ones from any of the given
+// release groups (see Torrent.ReleaseGroup) come first. Groups are matched case-insensitively
+// and the relative order of the torrents is kept otherwise.
+func PreferGroups(torrents []Torrent, groups []string) []Torrent {
+	preferred := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		preferred[strings.ToLower(group)] = struct{}{}
+	}
+	isPreferred := func(t Torrent) bool {
+		_, ok := preferred[strings.ToLower(t.ReleaseGroup())]
+		return ok
+	}
+
+	sorted := slices.Clone(torrents)
+	slices.SortStableFunc(sorted, func(a, b Torrent) int {
+		switch aOK, bOK := isPreferred(a), isPreferred(b); {
+		case aOK && !bOK:
+			return -1
+		case !aOK && bOK:
+			return 1
+		}
+		return 0
+	})
+	return sorted
+}
@@ -0,0 +1,93 @@
+package eztv
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCompareTo(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Torrent
+		keys []SortKey
+		want int
+	}{
+		{"no keys", Torrent{ID: 1}, Torrent{ID: 2}, nil, 0},
+		{"first key decides", Torrent{Season: "1", Episode: "9"}, Torrent{Season: "2", Episode: "1"}, []SortKey{SortKeySeason, SortKeyEpisode}, -1},
+		{"tie falls through", Torrent{Season: "2", Episode: "3"}, Torrent{Season: "2", Episode: "1"}, []SortKey{SortKeySeason, SortKeyEpisode}, 1},
+		{"tie on every key", Torrent{Season: "2", Episode: "1", Seeds: 5}, Torrent{Season: "02", Episode: "01", Seeds: 5}, []SortKey{SortKeySeason, SortKeyEpisode, SortKeySeeds}, 0},
+		{"third key decides", Torrent{Season: "2", Episode: "1", Seeds: 5}, Torrent{Season: "2", Episode: "1", Seeds: 7}, []SortKey{SortKeySeason, SortKeyEpisode, SortKeySeeds}, -1},
+		{"unparsed sorts first", Torrent{Episode: ""}, Torrent{Episode: "0"}, []SortKey{SortKeyEpisode}, -1},
+		{"both unparsed tie", Torrent{SizeBytes: "big", ID: 2}, Torrent{SizeBytes: "", ID: 1}, []SortKey{SortKeySize, SortKeyID}, 1},
+	}
+	for _, tt := range tests {
+		if got := tt.a.CompareTo(tt.b, tt.keys...); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+		if got := tt.b.CompareTo(tt.a, tt.keys...); got != -tt.want {
+			t.Errorf("%s, swapped: got %d, want %d", tt.name, got, -tt.want)
+		}
+	}
+}
+
+func TestCompareToSort(t *testing.T) {
+	torrents := []Torrent{
+		{ID: 1, Season: "2", Episode: "1", Seeds: 3},
+		{ID: 2, Season: "1", Episode: "2", Seeds: 9},
+		{ID: 3, Season: "1", Episode: "2", Seeds: 1},
+		{ID: 4, Season: "1", Episode: "1", Seeds: 5},
+		{ID: 5, Season: "x"},
+	}
+	slices.SortFunc(torrents, func(a, b Torrent) int {
+		return a.CompareTo(b, SortKeySeason, SortKeyEpisode, SortKeySeeds)
+	})
+	if got := ids(torrents); !slices.Equal(got, []int{5, 4, 3, 2, 1}) {
+		t.Fatalf("got %v, want [5 4 3 2 1]", got)
+	}
+}
+
+func TestPreferGroups(t *testing.T) {
+	torrents := []Torrent{
+		{ID: 1, Title: "Show.S01E01.1080p.WEB.h264-RANDOM"},
+		{ID: 2, Title: "Show.S01E01.1080p.WEB.h264-ntb[eztv].mkv"},
+		{ID: 3, Title: "Show.S01E01.720p.HDTV.x264"},
+		{ID: 4, Title: "Show.S01E01.720p.WEB.h264-GGEZ"},
+		{ID: 5, Title: "Show.S01E01.2160p.WEB.h265-NTb"},
+	}
+	sorted := PreferGroups(torrents, []string{"NTb", "ggez"})
+	if got := ids(sorted); !slices.Equal(got, []int{2, 4, 5, 1, 3}) {
+		t.Fatalf("got %v, want the trusted groups first in their original order [2 4 5 1 3]", got)
+	}
+	if got := ids(torrents); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("input was reordered to %v", got)
+	}
+}
+
+func TestGetTorrentsSort(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+	api.add(
+		Torrent{ID: 1, Seeds: 50, DateReleasedUnix: 300, SizeBytes: "1000"},
+		Torrent{ID: 2, Seeds: 10, DateReleasedUnix: 100, SizeBytes: "malformed"},
+		Torrent{ID: 3, Seeds: 50, DateReleasedUnix: 200, SizeBytes: "3000"},
+		Torrent{ID: 4, Seeds: 90, DateReleasedUnix: 300, SizeBytes: "1000"},
+		Torrent{ID: 5, Seeds: 10, DateReleasedUnix: 200, SizeBytes: ""},
+	)
+	c := testClient(t, api)
+
+	// Ties keep the order of the API, newest first.
+	for by, want := range map[SortBy][]int{
+		SortNone:      {5, 4, 3, 2, 1},
+		SortSeedsDesc: {4, 3, 1, 5, 2},
+		SortDateDesc:  {4, 1, 5, 3, 2},
+		SortSizeDesc:  {3, 4, 1, 5, 2},
+	} {
+		page, err := c.GetTorrents(context.Background(), URLOptions{Page: 1, Sort: by})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(page.Torrents); !slices.Equal(got, want) {
+			t.Errorf("sort %d: got %v, want %v", by, got, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package eztv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSEHeartbeatInterval is how often StreamSSE writes a comment to keep an idle connection alive.
+const SSEHeartbeatInterval = 15 * time.Second
+
+var ErrFlushingUnsupported = errors.New("response writer does not support flushing")
+
+// StreamSSE serves the TorrentStream opened with opts as server-sent events on w:
+//   - every torrent is written as a JSON encoded Torrent event with the torrent ID as the event ID,
+//     using the "snapshot" event type for torrents pushed in FullSnapshotMode;
+//   - every stream error is written as an "error" event with the error message;
+//   - a heartbeat comment is written every SSEHeartbeatInterval.
+//
+// It blocks until ctx is done, which should be the context of the request so that
+// the stream stops once the client disconnects, or the stream closes.
+// If w is not an http.Flusher, it returns ErrFlushingUnsupported before writing anything.
+func (c *Client) StreamSSE(ctx context.Context, opts StreamOptions, w http.ResponseWriter) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return ErrFlushingUnsupported
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream := c.TorrentStream(ctx, opts)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(SSEHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case s, ok := <-stream:
+			if !ok {
+				return nil
+			}
+			err = writeSSEEvent(w, s)
+		}
+		if err != nil {
+			return fmt.Errorf("write event: %w", err)
+		}
+		flusher.Flush()
+	}
+}
+
+// writeSSEEvent writes the pushed torrent or error as a single server-sent event.
+func writeSSEEvent(w io.Writer, s StreamTorrent) error {
+	var b strings.Builder
+	if s.Err != nil {
+		b.WriteString("event: error\n")
+		for _, line := range strings.Split(s.Err.Error(), "\n") {
+			b.WriteString("data: " + line + "\n")
+		}
+	} else {
+		data, err := json.Marshal(s.Torrent)
+		if err != nil {
+			return err
+		}
+		if s.Snapshot {
+			b.WriteString("event: snapshot\n")
+		}
+		b.WriteString("id: " + strconv.Itoa(s.ID) + "\n")
+		b.WriteString("data: " + string(data) + "\n")
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
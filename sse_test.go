@@ -0,0 +1,113 @@
+package eztv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteSSEEvent(t *testing.T) {
+	torrent := Torrent{ID: 7, Title: "Show.S01E01.720p"}
+	data, err := json.Marshal(torrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		s    StreamTorrent
+		want string
+	}{
+		{"torrent", StreamTorrent{Torrent: torrent}, "id: 7\ndata: " + string(data) + "\n\n"},
+		{"snapshot", StreamTorrent{Torrent: torrent, Snapshot: true}, "event: snapshot\nid: 7\ndata: " + string(data) + "\n\n"},
+		{"error", StreamTorrent{Err: errors.New("request failed")}, "event: error\ndata: request failed\n\n"},
+		{"multi-line error", StreamTorrent{Err: errors.New("first\nsecond")}, "event: error\ndata: first\ndata: second\n\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := writeSSEEvent(&b, tt.s); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStreamSSE(t *testing.T) {
+	c := testClient(t, newFakeAPI(3))
+	rec := httptest.NewRecorder()
+
+	// The stream closes after MaxDuration, which ends StreamSSE.
+	opts := StreamOptions{ImdbID: "tt1", RecheckInterval: time.Hour, MaxDuration: 200 * time.Millisecond}
+	if err := c.StreamSSE(context.Background(), opts, rec); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("got status %d and headers %v", rec.Code, rec.Header())
+	}
+	if !rec.Flushed {
+		t.Fatal("events were not flushed")
+	}
+
+	body := rec.Body.String()
+	if !strings.HasSuffix(body, "\n\n") {
+		t.Fatalf("body %q does not end with a complete event", body)
+	}
+	events := strings.Split(strings.TrimSuffix(body, "\n\n"), "\n\n")
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3:\n%s", len(events), body)
+	}
+	for i, event := range events {
+		lines := strings.Split(event, "\n")
+		if len(lines) != 2 || lines[0] != "id: "+strconv.Itoa(i+1) || !strings.HasPrefix(lines[1], "data: ") {
+			t.Fatalf("malformed event %q", event)
+		}
+		var torrent Torrent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &torrent); err != nil || torrent.ID != i+1 {
+			t.Fatalf("event %q holds torrent %d, %v, want %d", event, torrent.ID, err, i+1)
+		}
+	}
+}
+
+func TestStreamSSEClientDisconnect(t *testing.T) {
+	c := testClient(t, newFakeAPI(3))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.StreamSSE(ctx, StreamOptions{ImdbID: "tt1", RecheckInterval: time.Hour}, httptest.NewRecorder())
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("StreamSSE kept running after the request context was done")
+	}
+}
+
+// unflushableWriter is an http.ResponseWriter that is not an http.Flusher.
+type unflushableWriter struct {
+	http.ResponseWriter
+}
+
+func TestStreamSSEFlushingUnsupported(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := testClient(t, newFakeAPI(3)).StreamSSE(context.Background(), StreamOptions{ImdbID: "tt1"}, unflushableWriter{rec})
+	if !errors.Is(err, ErrFlushingUnsupported) {
+		t.Fatalf("got %v, want ErrFlushingUnsupported", err)
+	}
+	if rec.Body.Len() != 0 || len(rec.Header()) != 0 {
+		t.Fatalf("wrote %q with headers %v before failing", rec.Body, rec.Header())
+	}
+}
@@ -0,0 +1,747 @@
+package eztv
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// emittedIDsCapacity is the number of most recently emitted torrent IDs remembered by a Client.
+const emittedIDsCapacity = 10_000
+
+// DefaultSeenWindow is the default StreamOptions.SeenWindow.
+const DefaultSeenWindow = 100
+
+// GlobalStream returns a channel that will push new torrents as they are added to the EZTV API
+// for any show, polling the latest torrents feed every recheck interval.
+//
+// Only torrents added after the stream started are pushed, in ascending ID order.
+// Torrents that were already pushed by a TorrentStream of the same Client are skipped.
+//
+// If recheck is 0, it will default to StreamRecheckInterval constant.
+func (c *Client) GlobalStream(ctx context.Context, recheck time.Duration) <-chan StreamTorrent {
+	torrentsCh := make(chan StreamTorrent)
+
+	go func() {
+		defer close(torrentsCh)
+
+		if recheck == 0 {
+			recheck = StreamRecheckInterval
+		}
+
+		lastTorrentID := -1
+		for {
+			page, err := c.GetTorrents(ctx, URLOptions{
+				Page:  1,
+				Limit: MaxEZTVAPILimit,
+			})
+			switch {
+			case err != nil:
+				if !send(ctx, torrentsCh, StreamTorrent{Err: err}) {
+					return
+				}
+			case lastTorrentID == -1: // First poll only sets the starting point.
+				lastTorrentID = 0
+				if len(page.Torrents) > 0 {
+					lastTorrentID = page.Torrents[0].ID
+				}
+			default:
+				newest := lastTorrentID
+				for i := len(page.Torrents) - 1; i >= 0; i-- {
+					torrent := page.Torrents[i]
+					if torrent.ID <= lastTorrentID {
+						continue
+					}
+					newest = max(newest, torrent.ID)
+					if c.emitted.contains(torrent.ID) {
+						continue
+					}
+					if !send(ctx, torrentsCh, StreamTorrent{Torrent: torrent}) {
+						return
+					}
+					c.recordEmitted(torrent)
+				}
+				lastTorrentID = newest
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(recheck):
+			}
+		}
+	}()
+
+	return torrentsCh
+}
+
+// MultiTorrentStream merges a TorrentStream for each of opts into a single channel.
+// Torrents of the different shows are told apart by their ImdbID. Errors are pushed
+// with only the ImdbID of their StreamOptions set on the Torrent, without the "tt" prefix,
+// the way the API returns it.
+//
+// The streams run independently, so one of them failing or closing does not affect the others.
+// The channel is closed once all of them are closed, e.g. when ctx is cancelled.
+func (c *Client) MultiTorrentStream(ctx context.Context, opts []StreamOptions) <-chan StreamTorrent {
+	torrentsCh := make(chan StreamTorrent)
+
+	var wg sync.WaitGroup
+	for _, streamOptions := range opts {
+		stream := c.TorrentStream(ctx, streamOptions)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range stream {
+				if s.Err != nil {
+					s.ImdbID = strings.TrimPrefix(streamOptions.ImdbID, "tt")
+				}
+				if !send(ctx, torrentsCh, s) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(torrentsCh)
+	}()
+
+	return torrentsCh
+}
+
+// send pushes v onto ch, unless ctx is done first. It reports whether v was sent.
+func send[T any](ctx context.Context, ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recordEmitted records a torrent pushed onto a stream.
+func (c *Client) recordEmitted(t Torrent) {
+	c.emitted.add(t.ID)
+	c.metrics.AddTorrentsEmitted(c.name, 1)
+}
+
+// withJitter returns d moved by a random duration between -jitter and jitter, but never below zero.
+func withJitter(d, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return d
+	}
+	return max(d+time.Duration(rand.Int63n(2*int64(jitter)+1))-jitter, 0)
+}
+
+// emit pushes the torrent onto the stream and saves it as seen to the stream state.
+// Torrents saved as seen by an earlier run of the stream are skipped, and so are the ones
+// that are no upgrade of StreamOptions.CurrentBest.
+// It returns false if ctx was done before the stream was read.
+func (c *Client) emit(ctx context.Context, torrentsCh chan<- StreamTorrent, state *streamState, torrent Torrent) bool {
+	if state.seen(torrent.ID) {
+		return true
+	}
+	if state.best != nil && !upgradeBest(state.best, torrent, state.prefs) {
+		return true
+	}
+
+	if !state.pace(ctx) || !send(ctx, torrentsCh, StreamTorrent{Torrent: torrent, Err: nil}) {
+		return false
+	}
+	c.recordEmitted(torrent)
+
+	if err := state.saveSeen(ctx, torrent.ID); err != nil {
+		return send(ctx, torrentsCh, StreamTorrent{Err: err})
+	}
+	return true
+}
+
+// advance moves the stream's last torrent ID forward, pushing a failure to save it onto the stream.
+// It returns false if ctx was done before the stream was read.
+func (c *Client) advance(ctx context.Context, torrentsCh chan<- StreamTorrent, state *streamState, lastTorrentID int) bool {
+	if err := state.advance(ctx, lastTorrentID); err != nil {
+		return send(ctx, torrentsCh, StreamTorrent{Err: err})
+	}
+	return true
+}
+
+// streamState is the state of a single stream persisted to StreamOptions.StateStore.
+// With no store, nothing is persisted and no torrents are skipped.
+type streamState struct {
+	store   StateStore
+	imdbID  string
+	window  int
+	seenIDs []int
+	limiter *rate.Limiter
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+	// best is the copy of StreamOptions.CurrentBest the stream upgrades, nil if it emits every torrent.
+	best  map[SeasonEpisode]Torrent
+	prefs QualityPrefs
+	// progress is the last torrent ID saved to the store and reported to onProgress.
+	progress   int
+	onProgress func(lastTorrentID int)
+}
+
+func newStreamState(streamOptions StreamOptions, imdbID string) *streamState {
+	window := streamOptions.SeenWindow
+	if window <= 0 {
+		window = DefaultSeenWindow
+	}
+	state := &streamState{
+		store:      streamOptions.StateStore,
+		imdbID:     imdbID,
+		window:     window,
+		onProgress: streamOptions.OnProgress,
+		best:       maps.Clone(streamOptions.CurrentBest),
+		prefs:      streamOptions.UpgradePrefs,
+		now:        streamOptions.now,
+		sleep:      streamOptions.sleep,
+	}
+	if state.now == nil {
+		state.now = time.Now
+	}
+	if state.sleep == nil {
+		state.sleep = sleep
+	}
+	if streamOptions.MaxEmitRate > 0 {
+		state.limiter = rate.NewLimiter(streamOptions.MaxEmitRate, 1)
+	}
+	return state
+}
+
+// pace waits until the stream is allowed to push another torrent by its MaxEmitRate.
+// It returns false if ctx is done first.
+func (s *streamState) pace(ctx context.Context) bool {
+	if s.limiter == nil {
+		return true
+	}
+	r := s.limiter.ReserveN(s.now(), 1)
+	if delay := r.DelayFrom(s.now()); delay > 0 {
+		if err := s.sleep(ctx, delay); err != nil {
+			r.CancelAt(s.now())
+			return false
+		}
+	}
+	return true
+}
+
+// advance saves the stream's last torrent ID to the store and reports it to StreamOptions.OnProgress
+// if it moved past the last saved one. Every torrent up to it must have been emitted already,
+// so that resuming from it never misses a torrent.
+func (s *streamState) advance(ctx context.Context, lastTorrentID int) error {
+	if lastTorrentID <= s.progress {
+		return nil
+	}
+	if s.store != nil {
+		if err := s.store.Save(ctx, s.imdbID, lastTorrentID); err != nil {
+			return fmt.Errorf("save state: %w", err)
+		}
+	}
+	s.progress = lastTorrentID
+	if s.onProgress != nil {
+		s.onProgress(lastTorrentID)
+	}
+	return nil
+}
+
+// load reads the saved state of the stream and returns the ID of the newest torrent it emitted.
+func (s *streamState) load(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	lastTorrentID, err := s.store.Load(ctx, s.imdbID)
+	if err != nil {
+		return 0, fmt.Errorf("load state: %w", err)
+	}
+
+	if seenStore, ok := s.store.(SeenStore); ok {
+		ids, err := seenStore.LoadSeen(ctx, s.imdbID)
+		if err != nil {
+			return 0, fmt.Errorf("load seen torrents: %w", err)
+		}
+		s.seenIDs = ids[max(0, len(ids)-s.window):]
+	}
+
+	return lastTorrentID, nil
+}
+
+// upgradeBest makes the torrent the best release of each of its episodes in best that it is an upgrade of,
+// and reports whether there was any.
+func upgradeBest(best map[SeasonEpisode]Torrent, torrent Torrent, prefs QualityPrefs) bool {
+	upgraded := false
+	for _, ep := range torrent.episodes() {
+		if current, ok := best[ep]; ok && torrent.IsUpgrade(current, prefs) {
+			best[ep] = torrent
+			upgraded = true
+		}
+	}
+	return upgraded
+}
+
+// seen reports whether the torrent ID is one of the last emitted ones.
+func (s *streamState) seen(id int) bool {
+	return slices.Contains(s.seenIDs, id)
+}
+
+// saveSeen records the emitted torrent ID in the store, if it is a SeenStore.
+func (s *streamState) saveSeen(ctx context.Context, id int) error {
+	seenStore, ok := s.store.(SeenStore)
+	if !ok {
+		return nil
+	}
+
+	s.seenIDs = append(s.seenIDs, id)
+	if len(s.seenIDs) > s.window {
+		s.seenIDs = slices.Delete(s.seenIDs, 0, len(s.seenIDs)-s.window)
+	}
+	if err := seenStore.SaveSeen(ctx, s.imdbID, s.seenIDs); err != nil {
+		return fmt.Errorf("save seen torrents: %w", err)
+	}
+	return nil
+}
+
+// idSet is a concurrency safe set of IDs that holds at most its capacity of the most recently added IDs.
+type idSet struct {
+	mu    sync.Mutex
+	ids   map[int]struct{}
+	order []int
+	next  int
+}
+
+func newIDSet(capacity int) *idSet {
+	return &idSet{
+		ids:   make(map[int]struct{}, capacity),
+		order: make([]int, 0, capacity),
+	}
+}
+
+func (s *idSet) add(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ids[id]; ok {
+		return
+	}
+	if len(s.order) < cap(s.order) {
+		s.order = append(s.order, id)
+	} else {
+		delete(s.ids, s.order[s.next])
+		s.order[s.next] = id
+		s.next = (s.next + 1) % len(s.order)
+	}
+	s.ids[id] = struct{}{}
+}
+
+func (s *idSet) contains(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.ids[id]
+	return ok
+}
+
+// SupervisedStream works like TorrentStream, but restarts the underlying stream when it
+// has not made a successful poll within StreamOptions.WatchdogWindow, e.g. because of a wedged connection.
+// The restarted stream resumes from the newest torrent that was pushed, so nothing is re-delivered.
+func (c *Client) SupervisedStream(ctx context.Context, streamOptions StreamOptions) <-chan StreamTorrent {
+	torrentsCh := make(chan StreamTorrent)
+
+	go func() {
+		defer close(torrentsCh)
+
+		if streamOptions.MaxDuration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, streamOptions.MaxDuration)
+			defer cancel()
+			streamOptions.MaxDuration = 0
+		}
+
+		window := streamOptions.WatchdogWindow
+		if window == 0 {
+			recheckInterval := streamOptions.RecheckInterval
+			if recheckInterval == 0 {
+				recheckInterval = StreamRecheckInterval
+			}
+			window = 3 * recheckInterval
+		}
+
+		// The best releases are tracked across restarts, so that a restarted stream
+		// does not emit torrents that are no upgrade of the ones pushed before.
+		streamOptions.CurrentBest = maps.Clone(streamOptions.CurrentBest)
+
+		for {
+			polled := make(chan struct{}, 1)
+			streamOptions.onPoll = func() {
+				select {
+				case polled <- struct{}{}:
+				default:
+				}
+			}
+
+			streamCtx, cancel := context.WithCancel(ctx)
+			stream := c.torrentStream(streamCtx, streamOptions)
+			stalled := c.superviseStream(ctx, stream, polled, window, torrentsCh, &streamOptions)
+			cancel()
+			go func() {
+				for range stream { // Let the stalled stream exit.
+				}
+			}()
+
+			if !stalled {
+				return
+			}
+		}
+	}()
+
+	return torrentsCh
+}
+
+// superviseStream forwards torrents from stream onto torrentsCh, tracking the newest pushed torrent ID
+// and the best releases pushed in streamOptions.
+// It reports whether the stream has stalled and has to be restarted.
+func (c *Client) superviseStream(ctx context.Context, stream <-chan StreamTorrent, polled <-chan struct{}, window time.Duration, torrentsCh chan<- StreamTorrent, streamOptions *StreamOptions) bool {
+	watchdog := time.NewTimer(window)
+	defer watchdog.Stop()
+
+	resetWatchdog := func() {
+		if !watchdog.Stop() {
+			select {
+			case <-watchdog.C:
+			default:
+			}
+		}
+		watchdog.Reset(window)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-watchdog.C:
+			return true
+		case <-polled:
+			resetWatchdog()
+		case s, ok := <-stream:
+			if !ok {
+				return false
+			}
+			// Snapshots re-emit torrents the stream already went past, and StrictChronological
+			// emits them in date order, so the position only ever moves up to the newest torrent.
+			if s.Err == nil && !s.Snapshot {
+				streamOptions.LastTorrentID = max(streamOptions.LastTorrentID, s.ID)
+				if streamOptions.CurrentBest != nil {
+					upgradeBest(streamOptions.CurrentBest, s.Torrent, streamOptions.UpgradePrefs)
+				}
+			}
+			if !send(ctx, torrentsCh, s) {
+				return false
+			}
+			resetWatchdog()
+		}
+	}
+}
+
+const (
+	// MinAutoRecheckInterval and MaxAutoRecheckInterval bound the RecheckInterval recommended by AutoStreamOptions.
+	MinAutoRecheckInterval = time.Minute
+	MaxAutoRecheckInterval = 6 * time.Hour
+)
+
+// AutoStreamOptions returns StreamOptions for the show with a RecheckInterval recommended
+// from its release cadence: a tenth of the average time between its latest releases,
+// clamped to MinAutoRecheckInterval and MaxAutoRecheckInterval.
+//
+// If the cadence cannot be measured, e.g. the show has less than two dated torrents,
+// RecheckInterval is set to StreamRecheckInterval.
+func (c *Client) AutoStreamOptions(ctx context.Context, imdbID string) (StreamOptions, error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return StreamOptions{}, ErrMissingImdbID
+	}
+
+	page, err := c.GetTorrents(ctx, URLOptions{
+		ImdbID: imdbID,
+		Page:   1,
+		Limit:  MaxEZTVAPILimit,
+	})
+	if err != nil {
+		return StreamOptions{}, err
+	}
+
+	recheckInterval := StreamRecheckInterval
+	if gap, ok := averageReleaseGap(page.Torrents); ok {
+		recheckInterval = min(max(gap/10, MinAutoRecheckInterval), MaxAutoRecheckInterval)
+	}
+
+	return StreamOptions{
+		ImdbID:          imdbID,
+		RecheckInterval: recheckInterval,
+	}, nil
+}
+
+// averageReleaseGap returns the average time between the releases of the torrents.
+// Torrents without a release date are ignored.
+func averageReleaseGap(torrents []Torrent) (time.Duration, bool) {
+	oldest, newest, dated := math.MaxInt, math.MinInt, 0
+	for _, torrent := range torrents {
+		if torrent.DateReleasedUnix == 0 {
+			continue
+		}
+		oldest = min(oldest, torrent.DateReleasedUnix)
+		newest = max(newest, torrent.DateReleasedUnix)
+		dated++
+	}
+	if dated < 2 || newest == oldest {
+		return 0, false
+	}
+
+	return time.Duration(newest-oldest) * time.Second / time.Duration(dated-1), true
+}
+
+// sharedStreams is the registry of streams shared between subscribers WithSharedStreams, keyed by ImdbID.
+//
+// Locks are never held while a torrent is handed to a subscriber, so that a subscriber which does not
+// read only holds back the poller it is subscribed to, never subscribing to other streams.
+type sharedStreams struct {
+	mu      sync.Mutex
+	streams map[string]*sharedStream
+}
+
+// sharedStream is a single poller whose torrents are fanned out to all of its subscribers.
+type sharedStream struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+	cancel      context.CancelFunc
+	// done is closed once the stream has ended and all of its subscribers were closed.
+	done chan struct{}
+}
+
+// subscriber is a single consumer of a shared stream. Its channel is closed once, either when
+// its context is done or when the shared stream ends.
+type subscriber struct {
+	ctx context.Context
+	ch  chan StreamTorrent
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// subscribe returns a channel that receives the torrents of the shared stream for the ImdbID,
+// starting the stream with streamOptions if it is not running yet.
+func (r *sharedStreams) subscribe(ctx context.Context, c *Client, streamOptions StreamOptions) <-chan StreamTorrent {
+	key := strings.TrimPrefix(streamOptions.ImdbID, "tt")
+	sub := &subscriber{ctx: ctx, ch: make(chan StreamTorrent)}
+
+	var st *sharedStream
+	for {
+		var ok bool
+		r.mu.Lock()
+		st, ok = r.streams[key]
+		if !ok {
+			// The poller outlives the subscriber that started it, until every subscriber is gone.
+			streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+			st = &sharedStream{
+				subscribers: map[*subscriber]struct{}{sub: {}},
+				cancel:      cancel,
+				done:        make(chan struct{}),
+			}
+			r.streams[key] = st
+			r.mu.Unlock()
+
+			go r.fanOut(key, st, c.torrentStream(streamCtx, streamOptions))
+			break
+		}
+		r.mu.Unlock()
+
+		if st.add(sub) {
+			break
+		}
+		// The stream stopped in the meantime, so start over with a new one.
+		r.remove(key, st)
+	}
+
+	go r.unsubscribeOnDone(key, st, sub)
+
+	return sub.ch
+}
+
+// remove deletes the shared stream from the registry, unless it was already replaced by another one.
+func (r *sharedStreams) remove(key string, st *sharedStream) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.streams[key] == st {
+		delete(r.streams, key)
+	}
+}
+
+// add subscribes sub to the stream. It returns false if the stream has stopped.
+func (st *sharedStream) add(sub *subscriber) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.closed {
+		return false
+	}
+	st.subscribers[sub] = struct{}{}
+	return true
+}
+
+// fanOut pushes every torrent of the stream to all subscribers, closing them once the stream ends.
+func (r *sharedStreams) fanOut(key string, st *sharedStream, stream <-chan StreamTorrent) {
+	for s := range stream {
+		st.mu.Lock()
+		subs := make([]*subscriber, 0, len(st.subscribers))
+		for sub := range st.subscribers {
+			subs = append(subs, sub)
+		}
+		st.mu.Unlock()
+
+		for _, sub := range subs {
+			sub.send(s)
+		}
+	}
+
+	r.remove(key, st)
+
+	st.mu.Lock()
+	subs := st.subscribers
+	st.subscribers = nil
+	st.closed = true
+	st.mu.Unlock()
+
+	for sub := range subs {
+		sub.close()
+	}
+	close(st.done)
+}
+
+// unsubscribeOnDone removes the subscriber once its context is done,
+// stopping the stream when it was the last one. It returns once the stream has ended too.
+func (r *sharedStreams) unsubscribeOnDone(key string, st *sharedStream, sub *subscriber) {
+	select {
+	case <-sub.ctx.Done():
+	case <-st.done:
+		return
+	}
+	sub.close()
+
+	st.mu.Lock()
+	if st.closed {
+		st.mu.Unlock()
+		return
+	}
+	delete(st.subscribers, sub)
+	last := len(st.subscribers) == 0
+	if last {
+		// Nobody can subscribe to the stream anymore, as it is being stopped.
+		st.closed = true
+	}
+	st.mu.Unlock()
+
+	if last {
+		st.cancel()
+		r.remove(key, st)
+	}
+}
+
+// send hands the torrent to the subscriber, giving up once its context is done.
+func (sub *subscriber) send(s StreamTorrent) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return
+	}
+	select {
+	case sub.ch <- s:
+	case <-sub.ctx.Done():
+	}
+}
+
+// close closes the subscriber's channel, unless it was closed already.
+func (sub *subscriber) close() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if !sub.closed {
+		close(sub.ch)
+		sub.closed = true
+	}
+}
+
+// Stream is a TorrentStream that remembers the most recently pushed torrents,
+// so that consumers which start reading late can catch up.
+type Stream struct {
+	torrents <-chan StreamTorrent
+
+	mu     sync.Mutex
+	recent []Torrent
+	next   int
+	size   int
+}
+
+// OpenStream starts a TorrentStream and wraps it into a Stream that keeps the last
+// StreamOptions.RecentBufferSize pushed torrents available through Recent.
+func (c *Client) OpenStream(ctx context.Context, streamOptions StreamOptions) *Stream {
+	torrentsCh := make(chan StreamTorrent)
+	s := &Stream{
+		torrents: torrentsCh,
+		recent:   make([]Torrent, 0, max(streamOptions.RecentBufferSize, 0)),
+		size:     max(streamOptions.RecentBufferSize, 0),
+	}
+
+	go func() {
+		defer close(torrentsCh)
+
+		for st := range c.TorrentStream(ctx, streamOptions) {
+			if st.Err == nil {
+				s.remember(st.Torrent)
+			}
+			if !send(ctx, torrentsCh, st) {
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+// Torrents returns the channel the stream pushes torrents onto.
+func (s *Stream) Torrents() <-chan StreamTorrent {
+	return s.torrents
+}
+
+// Recent returns up to StreamOptions.RecentBufferSize most recently pushed torrents, oldest first.
+func (s *Stream) Recent() []Torrent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recent := make([]Torrent, 0, len(s.recent))
+	recent = append(recent, s.recent[s.next:]...)
+	return append(recent, s.recent[:s.next]...)
+}
+
+func (s *Stream) remember(t Torrent) {
+	if s.size == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.recent) < s.size {
+		s.recent = append(s.recent, t)
+		return
+	}
+	s.recent[s.next] = t
+	s.next = (s.next + 1) % s.size
+}
@@ -0,0 +1,1187 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// receive reads the next torrent of the stream, failing the test if none arrives in time.
+func receive(t *testing.T, stream <-chan StreamTorrent) StreamTorrent {
+	t.Helper()
+	select {
+	case s, ok := <-stream:
+		if !ok {
+			t.Fatal("stream closed")
+		}
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the stream")
+	}
+	return StreamTorrent{}
+}
+
+// closed waits for the stream to be closed, discarding anything still pushed onto it.
+func closed(t *testing.T, stream <-chan StreamTorrent) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-stream:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the stream to close")
+		}
+	}
+}
+
+func TestSharedStreamsSinglePoller(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api, WithSharedStreams())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond}
+	first := c.TorrentStream(ctx, opts)
+	second := c.TorrentStream(ctx, opts)
+
+	api.add(Torrent{ID: 4})
+	// Subscribers are handed torrents one after another, so they are read concurrently.
+	got := make(chan StreamTorrent, 2)
+	for _, stream := range []<-chan StreamTorrent{first, second} {
+		go func() { got <- <-stream }()
+	}
+	for range 2 {
+		if s := receive(t, got); s.Err != nil || s.ID != 4 {
+			t.Fatalf("got %+v, want torrent 4", s)
+		}
+	}
+
+	// Both subscribers share the poller, so each recheck is a single request for page 1.
+	seen := make(map[string]int)
+	for _, q := range api.requests() {
+		seen[q.Get("page")+"/"+q.Get("limit")]++
+	}
+	if seen["1/1"] == 0 {
+		t.Fatalf("no rechecks in %v", api.requests())
+	}
+	c.shared.mu.Lock()
+	n := len(c.shared.streams)
+	c.shared.mu.Unlock()
+	if n != 1 {
+		t.Fatalf("%d shared streams, want 1", n)
+	}
+
+	cancel()
+	closed(t, first)
+	closed(t, second)
+}
+
+func TestSharedStreamsSlowSubscriber(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api, WithSharedStreams())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond}
+	slow := c.TorrentStream(ctx, opts) // Never read until the end.
+	api.add(Torrent{ID: 4})
+
+	// Let the poller block on handing torrent 4 to the slow subscriber.
+	time.Sleep(100 * time.Millisecond)
+
+	subscribed := make(chan (<-chan StreamTorrent))
+	go func() {
+		subscribed <- c.TorrentStream(ctx, opts)
+		subscribed <- c.TorrentStream(ctx, StreamOptions{ImdbID: "tt2", LastTorrentID: 4, RecheckInterval: time.Hour})
+	}()
+	for range 2 {
+		select {
+		case <-subscribed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("subscribing blocked on a subscriber that does not read")
+		}
+	}
+
+	if s := receive(t, slow); s.ID != 4 {
+		t.Fatalf("got %+v, want torrent 4", s)
+	}
+}
+
+func TestSharedStreamsResubscribeAfterStop(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api, WithSharedStreams())
+	opts := StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := c.TorrentStream(ctx, opts)
+	cancel()
+	closed(t, first)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	second := c.TorrentStream(ctx, opts)
+	api.add(Torrent{ID: 4})
+	if s := receive(t, second); s.Err != nil || s.ID != 4 {
+		t.Fatalf("got %+v, want torrent 4", s)
+	}
+}
+
+func TestSharedStreamsEndedStreamReleasesSubscribers(t *testing.T) {
+	c := testClient(t, newFakeAPI(3), WithSharedStreams())
+
+	// The subscriber's context is never done, so only the end of the stream can release it.
+	stream := c.TorrentStream(context.Background(), StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond, MaxDuration: 50 * time.Millisecond})
+	closed(t, stream)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		buf := make([]byte, 1<<20)
+		if !strings.Contains(string(buf[:runtime.Stack(buf, true)]), "unsubscribeOnDone") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the subscriber of the ended stream is still waiting for its context")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// recordingStore is a MemoryStateStore that records every saved torrent ID and fails to save failID.
+type recordingStore struct {
+	*MemoryStateStore
+
+	mu     sync.Mutex
+	saved  []int
+	failID int
+}
+
+func (s *recordingStore) Save(ctx context.Context, imdbID string, lastTorrentID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lastTorrentID == s.failID {
+		return errors.New("store unavailable")
+	}
+	s.saved = append(s.saved, lastTorrentID)
+	return s.MemoryStateStore.Save(ctx, imdbID, lastTorrentID)
+}
+
+func (s *recordingStore) savedIDs() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.saved)
+}
+
+func TestStreamStateStoreStrictChronological(t *testing.T) {
+	api := newFakeAPI(5)
+	// The newest torrent by ID was released first, so it is emitted first.
+	api.torrents[0].DateReleasedUnix = 1
+	store := &recordingStore{MemoryStateStore: NewMemoryStateStore()}
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", StrictChronological: true, StateStore: store, RecheckInterval: time.Hour})
+	var got []int
+	for range 5 {
+		s := receive(t, stream)
+		if s.Err != nil {
+			t.Fatal(s.Err)
+		}
+		got = append(got, s.ID)
+		if len(got) < 5 {
+			// Torrents older by ID have not been delivered yet, so nothing may be saved.
+			if saved := store.savedIDs(); len(saved) > 0 {
+				t.Fatalf("saved %v after emitting only %v", saved, got)
+			}
+		}
+	}
+	if !slices.Equal(got, []int{5, 1, 2, 3, 4}) {
+		t.Fatalf("emitted %v, want [5 1 2 3 4]", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(store.savedIDs()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if saved := store.savedIDs(); !slices.Equal(saved, []int{5}) {
+		t.Fatalf("saved %v, want [5]", saved)
+	}
+}
+
+func TestStreamSeenStoreRestart(t *testing.T) {
+	api := newFakeAPI(2)
+	// Saving the watermark of torrent 3 fails, as if the process crashed right after emitting it.
+	store := &recordingStore{MemoryStateStore: NewMemoryStateStore(), failID: 3}
+	if err := store.Save(context.Background(), "1", 2); err != nil {
+		t.Fatal(err)
+	}
+	c := testClient(t, api)
+	opts := StreamOptions{ImdbID: "tt1", StateStore: store, RecheckInterval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := c.TorrentStream(ctx, opts)
+	api.add(Torrent{ID: 3})
+	if s := receive(t, stream); s.Err != nil || s.ID != 3 {
+		t.Fatalf("got %+v, want torrent 3", s)
+	}
+	if s := receive(t, stream); s.Err == nil {
+		t.Fatalf("got %+v, want the error saving the state", s)
+	}
+	cancel()
+	closed(t, stream)
+
+	if id, _ := store.Load(context.Background(), "1"); id != 2 {
+		t.Fatalf("saved state %d, want 2", id)
+	}
+
+	store.mu.Lock()
+	store.failID = 0
+	store.mu.Unlock()
+
+	// The restarted stream resumes from 2, but skips torrent 3, which it saved as seen.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	stream = c.TorrentStream(ctx, opts)
+	api.add(Torrent{ID: 4})
+	if s := receive(t, stream); s.Err != nil || s.ID != 4 {
+		t.Fatalf("got %+v, want torrent 4", s)
+	}
+}
+
+// receiveIDs reads the next n torrents of the stream and returns their IDs.
+func receiveIDs(t *testing.T, stream <-chan StreamTorrent, n int) []int {
+	t.Helper()
+	got := make([]int, 0, n)
+	for range n {
+		s := receive(t, stream)
+		if s.Err != nil {
+			t.Fatal(s.Err)
+		}
+		got = append(got, s.ID)
+	}
+	return got
+}
+
+// ascending returns the IDs from to to in ascending order.
+func ascending(from, to int) []int {
+	ids := make([]int, 0, to-from+1)
+	for id := from; id <= to; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestStreamLowMemory(t *testing.T) {
+	for _, lowMemory := range []bool{false, true} {
+		api := newFakeAPI(250)
+		c := testClient(t, api)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", LowMemory: lowMemory, RecheckInterval: time.Hour})
+		if got := receiveIDs(t, stream, 250); !slices.Equal(got, ascending(1, 250)) {
+			t.Fatalf("low memory %t: got %v, want 1 to 250", lowMemory, got)
+		}
+
+		wantLimit := strconv.Itoa(MaxEZTVAPILimit)
+		if lowMemory {
+			wantLimit = strconv.Itoa(LowMemoryResyncLimit)
+		}
+		resync := api.requests()[1:] // Skip the probe for the torrents count.
+		for _, q := range resync {
+			if q.Get("limit") != wantLimit {
+				t.Fatalf("low memory %t: requested %v, want limit %s", lowMemory, q, wantLimit)
+			}
+		}
+		if want := pageCount(250, MaxEZTVAPILimit); !lowMemory && len(resync) != want {
+			t.Fatalf("fetched %d pages, want %d", len(resync), want)
+		}
+		if want := pageCount(250, LowMemoryResyncLimit); lowMemory && len(resync) != want {
+			t.Fatalf("fetched %d pages in low memory mode, want %d", len(resync), want)
+		}
+	}
+}
+
+func TestStreamDebounce(t *testing.T) {
+	const debounce = 200 * time.Millisecond
+	api := newFakeAPI(3)
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond, Debounce: debounce})
+	var lastAdded time.Time
+	for id := 4; id <= 6; id++ {
+		api.add(Torrent{ID: id})
+		lastAdded = time.Now()
+		time.Sleep(debounce / 4)
+	}
+
+	first := receive(t, stream)
+	if elapsed := time.Since(lastAdded); elapsed < debounce {
+		t.Fatalf("torrent %d was emitted %s after the last one appeared, want it held back for %s", first.ID, elapsed, debounce)
+	}
+	if got := append([]int{first.ID}, receiveIDs(t, stream, 2)...); !slices.Equal(got, []int{4, 5, 6}) {
+		t.Fatalf("got %v, want [4 5 6]", got)
+	}
+}
+
+func TestStreamMaxDuration(t *testing.T) {
+	const maxDuration = 100 * time.Millisecond
+	c := testClient(t, newFakeAPI(3))
+
+	start := time.Now()
+	stream := c.TorrentStream(context.Background(), StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond, MaxDuration: maxDuration})
+	closed(t, stream)
+	if elapsed := time.Since(start); elapsed < maxDuration || elapsed > maxDuration+time.Second {
+		t.Fatalf("stream closed after %s, want %s", elapsed, maxDuration)
+	}
+}
+
+func TestGlobalStreamSkipsShowStreamTorrents(t *testing.T) {
+	api := newFakeAPI(3)
+	// The first poll of the global feed sets its starting point, every later one waits for release,
+	// so that the show stream is guaranteed to push torrent 4 before the global stream sees it.
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	c := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("imdb_id") != "" {
+			api.ServeHTTP(w, r)
+			return
+		}
+		first := false
+		once.Do(func() { first = true })
+		if !first {
+			<-release
+		}
+		api.ServeHTTP(w, r)
+		if first {
+			close(started)
+		}
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	global := c.GlobalStream(ctx, 10*time.Millisecond)
+	<-started
+	show := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond})
+	api.add(Torrent{ID: 4, ImdbID: "tt1"}, Torrent{ID: 5, ImdbID: "tt2"})
+	if s := receive(t, show); s.Err != nil || s.ID != 4 {
+		t.Fatalf("got %+v from the show stream, want torrent 4", s)
+	}
+
+	close(release)
+	if s := receive(t, global); s.Err != nil || s.ID != 5 {
+		t.Fatalf("got %+v from the global stream, want torrent 5 only", s)
+	}
+	cancel()
+	closed(t, global)
+}
+
+// wedgingAPI serves api until it is wedged. Then its requests hang until they are cancelled,
+// like over a wedged connection, which only the stream giving up on it ends.
+type wedgingAPI struct {
+	api *fakeAPI
+
+	mu      sync.Mutex
+	wedged  bool
+	aborted int
+}
+
+func (w *wedgingAPI) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	wedged := w.wedged
+	w.mu.Unlock()
+	if wedged {
+		<-r.Context().Done()
+		w.mu.Lock()
+		w.aborted++
+		w.mu.Unlock()
+		return
+	}
+	w.api.ServeHTTP(rw, r)
+}
+
+// stall wedges the API until a stream gives up on one of its requests.
+func (w *wedgingAPI) stall(t *testing.T) {
+	t.Helper()
+	w.mu.Lock()
+	w.wedged, w.aborted = true, 0
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.wedged = false
+		w.mu.Unlock()
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		n := w.aborted
+		w.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the stalled stream was not restarted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSupervisedStreamRestartsStalledStream(t *testing.T) {
+	api := newFakeAPI(3)
+	wedging := &wedgingAPI{api: api}
+	c := testClient(t, wedging)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.SupervisedStream(ctx, StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond, WatchdogWindow: 100 * time.Millisecond})
+	api.add(Torrent{ID: 4})
+	if s := receive(t, stream); s.Err != nil || s.ID != 4 {
+		t.Fatalf("got %+v, want torrent 4", s)
+	}
+
+	wedging.stall(t)
+
+	// The restarted stream resumes from torrent 4 instead of re-syncing from the start.
+	api.add(Torrent{ID: 5})
+	if s := receive(t, stream); s.Err != nil || s.ID != 5 {
+		t.Fatalf("got %+v, want torrent 5", s)
+	}
+	cancel()
+	closed(t, stream)
+}
+
+func TestSupervisedStreamRestartsStrictChronological(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+	api.add(
+		Torrent{ID: 1, DateReleasedUnix: 1_700_000_020},
+		Torrent{ID: 2, DateReleasedUnix: 1_700_000_030},
+		Torrent{ID: 3, DateReleasedUnix: 1_700_000_010},
+	)
+	wedging := &wedgingAPI{api: api}
+	c := testClient(t, wedging)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.SupervisedStream(ctx, StreamOptions{ImdbID: "tt1", StrictChronological: true, RecheckInterval: 10 * time.Millisecond, WatchdogWindow: 100 * time.Millisecond})
+	if got := receiveIDs(t, stream, 3); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Fatalf("got %v, want [3 1 2] in date order", got)
+	}
+
+	wedging.stall(t)
+
+	// The restarted stream resumes from the newest torrent, 3, not from the last one pushed.
+	api.add(Torrent{ID: 4, DateReleasedUnix: 1_700_000_040})
+	if s := receive(t, stream); s.Err != nil || s.ID != 4 {
+		t.Fatalf("got %+v, want torrent 4", s)
+	}
+	cancel()
+	closed(t, stream)
+}
+
+func TestSupervisedStreamRestartsCurrentBest(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+	api.add(Torrent{ID: 1, Title: "Show.S01E01.720p.HDTV.x264-GRP", Season: "1", Episode: "1"})
+	wedging := &wedgingAPI{api: api}
+	c := testClient(t, wedging)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.SupervisedStream(ctx, StreamOptions{
+		ImdbID:          "tt1",
+		RecheckInterval: 10 * time.Millisecond,
+		WatchdogWindow:  100 * time.Millisecond,
+		CurrentBest: map[SeasonEpisode]Torrent{
+			{1, 1}: {Title: "Show.S01E01.480p.HDTV.x264-GRP", Season: "1", Episode: "1"},
+		},
+	})
+	if s := receive(t, stream); s.Err != nil || s.ID != 1 {
+		t.Fatalf("got %+v, want torrent 1", s)
+	}
+
+	wedging.stall(t)
+
+	// The restarted stream knows that torrent 1 became the best release.
+	api.add(
+		Torrent{ID: 2, Title: "Show.S01E01.720p.HDTV.x264-OTHER", Season: "1", Episode: "1"},
+		Torrent{ID: 3, Title: "Show.S01E01.1080p.HDTV.x264-GRP", Season: "1", Episode: "1"},
+	)
+	if s := receive(t, stream); s.Err != nil || s.ID != 3 {
+		t.Fatalf("got %+v, want torrent 3", s)
+	}
+	cancel()
+	closed(t, stream)
+}
+
+func TestStreamKnownTorrentCount(t *testing.T) {
+	api := newFakeAPI(150)
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", KnownTorrentCount: 150, RecheckInterval: time.Hour})
+	if got := receiveIDs(t, stream, 150); !slices.Equal(got, ascending(1, 150)) {
+		t.Fatalf("got %v, want 1 to 150", got)
+	}
+
+	var pages []string
+	for _, q := range api.requests() {
+		pages = append(pages, q.Get("page")+"/"+q.Get("limit"))
+	}
+	if !slices.Equal(pages, []string{"2/100", "1/100"}) {
+		t.Fatalf("requested pages %v, want [2/100 1/100] without the limit-1 probe", pages)
+	}
+}
+
+func TestStreamStrictChronological(t *testing.T) {
+	api := newFakeAPI(250)
+	// Release dates run against the IDs across page boundaries, with ties broken by ID.
+	for i := range api.torrents {
+		api.torrents[i].DateReleasedUnix = 1_700_000_000 + (api.torrents[i].ID*37)%50
+	}
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", StrictChronological: true, RecheckInterval: time.Hour})
+	var got []Torrent
+	for range 250 {
+		s := receive(t, stream)
+		if s.Err != nil {
+			t.Fatal(s.Err)
+		}
+		got = append(got, s.Torrent)
+	}
+
+	byDate := func(a, b Torrent) int { return a.CompareTo(b, SortKeyDate, SortKeyID) }
+	if !slices.IsSortedFunc(got, byDate) {
+		t.Fatalf("emitted %v, want them sorted by release date and then ID", ids(got))
+	}
+	emitted := ids(got)
+	slices.Sort(emitted)
+	if !slices.Equal(emitted, ascending(1, 250)) {
+		t.Fatalf("emitted %v, want every torrent once", emitted)
+	}
+}
+
+func TestStreamStrictChronologicalRetry(t *testing.T) {
+	api := newFakeAPI(250)
+	for i := range api.torrents {
+		api.torrents[i].DateReleasedUnix = 1_700_000_000 + (api.torrents[i].ID*37)%50
+	}
+	var failures atomic.Int32
+	failPage2 := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" && failures.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	c := testClient(t, failPage2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The failed re-sync emits none of its torrents, and the stream does not go on
+	// without them, but tries the re-sync again.
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", StrictChronological: true, RecheckInterval: 10 * time.Millisecond})
+	if s := receive(t, stream); s.Err == nil {
+		t.Fatalf("got %+v, want the error of page 2", s)
+	}
+	var got []Torrent
+	for range 250 {
+		s := receive(t, stream)
+		if s.Err != nil {
+			t.Fatal(s.Err)
+		}
+		got = append(got, s.Torrent)
+	}
+	if !slices.IsSortedFunc(got, func(a, b Torrent) int { return a.CompareTo(b, SortKeyDate, SortKeyID) }) {
+		t.Fatalf("emitted %v, want the retried re-sync sorted by release date and then ID", ids(got))
+	}
+
+	api.add(Torrent{ID: 251})
+	if s := receive(t, stream); s.Err != nil || s.ID != 251 {
+		t.Fatalf("got %+v, want torrent 251", s)
+	}
+}
+
+func TestAutoStreamOptions(t *testing.T) {
+	released := func(imdbID string, gap time.Duration) []Torrent {
+		var torrents []Torrent
+		for i := range 5 {
+			torrents = append(torrents, Torrent{ImdbID: imdbID, DateReleasedUnix: 1_700_000_000 - i*int(gap/time.Second)})
+		}
+		return torrents
+	}
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+	api.torrents = append(api.torrents, released("tt1", time.Hour)...)
+	api.torrents = append(api.torrents, released("tt2", 24*time.Hour)...)
+	api.torrents = append(api.torrents, Torrent{ImdbID: "tt3", DateReleasedUnix: 1_700_000_000})
+	c := testClient(t, api)
+
+	recheck := func(imdbID string) time.Duration {
+		t.Helper()
+		opts, err := c.AutoStreamOptions(context.Background(), imdbID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return opts.RecheckInterval
+	}
+	frequent, rare := recheck("tt1"), recheck("tt2")
+	if frequent != 6*time.Minute || rare != 144*time.Minute {
+		t.Fatalf("got recheck intervals %s and %s, want a tenth of the release gaps", frequent, rare)
+	}
+	if opts, err := c.AutoStreamOptions(context.Background(), "tt3"); err != nil || opts.RecheckInterval != StreamRecheckInterval || opts.ImdbID != "3" {
+		t.Fatalf("got %+v, %v for an unmeasured cadence, want StreamRecheckInterval", opts, err)
+	}
+}
+
+func TestStreamPollTimeout(t *testing.T) {
+	api := newFakeAPI(3)
+	var hung atomic.Bool
+	c := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hung.CompareAndSwap(false, true) { // The first poll hangs until it is given up on.
+			<-r.Context().Done()
+			return
+		}
+		api.ServeHTTP(w, r)
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond, PollTimeout: 50 * time.Millisecond})
+	if s := receive(t, stream); !errors.Is(s.Err, context.DeadlineExceeded) {
+		t.Fatalf("got %+v, want the poll to time out", s)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("poll timed out after %s, want 50ms", elapsed)
+	}
+
+	// The stream carries on after the timed out poll.
+	api.add(Torrent{ID: 4})
+	if s := receive(t, stream); s.Err != nil || s.ID != 4 {
+		t.Fatalf("got %+v, want torrent 4", s)
+	}
+}
+
+func TestStreamFullSnapshotMode(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", LastTorrentID: 3, FullSnapshotMode: true, RecheckInterval: 10 * time.Millisecond})
+	snapshot := func() []int {
+		t.Helper()
+		var got []int
+		for range 3 {
+			s := receive(t, stream)
+			if s.Err != nil || !s.Snapshot {
+				t.Fatalf("got %+v, want a snapshot torrent", s)
+			}
+			got = append(got, s.ID)
+		}
+		return got
+	}
+
+	// Every poll emits the whole page, including torrents emitted before.
+	for range 2 {
+		if got := snapshot(); !slices.Equal(got, []int{3, 2, 1}) {
+			t.Fatalf("got snapshot %v, want [3 2 1]", got)
+		}
+	}
+}
+
+func TestOpenStreamRecent(t *testing.T) {
+	api := newFakeAPI(5)
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.OpenStream(ctx, StreamOptions{ImdbID: "tt1", RecentBufferSize: 3, RecheckInterval: 10 * time.Millisecond})
+	if got := receiveIDs(t, stream.Torrents(), 5); !slices.Equal(got, ascending(1, 5)) {
+		t.Fatalf("got %v, want 1 to 5", got)
+	}
+	if got := ids(stream.Recent()); !slices.Equal(got, []int{3, 4, 5}) {
+		t.Fatalf("got recent %v, want the last 3 [3 4 5]", got)
+	}
+
+	api.add(Torrent{ID: 6})
+	receiveIDs(t, stream.Torrents(), 1)
+	if got := ids(stream.Recent()); !slices.Equal(got, []int{4, 5, 6}) {
+		t.Fatalf("got recent %v, want torrent 3 evicted for 6", got)
+	}
+
+	unbuffered := c.OpenStream(ctx, StreamOptions{ImdbID: "tt1", LastTorrentID: 5, RecheckInterval: 10 * time.Millisecond})
+	receiveIDs(t, unbuffered.Torrents(), 1)
+	if recent := unbuffered.Recent(); len(recent) != 0 {
+		t.Fatalf("got recent %v without a RecentBufferSize, want none", ids(recent))
+	}
+}
+
+func TestStreamResyncCappedLimit(t *testing.T) {
+	api := newFakeAPI(230)
+	api.maxLimit = 50
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", RecheckInterval: time.Hour})
+	if got := receiveIDs(t, stream, 230); !slices.Equal(got, ascending(1, 230)) {
+		t.Fatalf("got %v, want 1 to 230 once each", got)
+	}
+
+	var pages []string
+	for _, q := range api.requests() {
+		pages = append(pages, q.Get("page")+"/"+q.Get("limit"))
+	}
+	// After the probe, the last page for the requested limit reveals the cap, and the re-sync starts over with it.
+	if want := []string{"1/1", "3/100", "5/50", "4/50", "3/50", "2/50", "1/50"}; !slices.Equal(pages, want) {
+		t.Fatalf("requested pages %v, want %v", pages, want)
+	}
+}
+
+func TestStreamDeliversEveryNewTorrent(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", LastTorrentID: 3, RecheckInterval: 10 * time.Millisecond})
+	api.add(Torrent{ID: 4}, Torrent{ID: 5}, Torrent{ID: 6}, Torrent{ID: 7}, Torrent{ID: 8})
+	if got := receiveIDs(t, stream, 5); !slices.Equal(got, []int{4, 5, 6, 7, 8}) {
+		t.Fatalf("got %v, want [4 5 6 7 8]", got)
+	}
+
+	// More new torrents than fit on a page between two rechecks.
+	var burst []Torrent
+	for id := 9; id <= 158; id++ {
+		burst = append(burst, Torrent{ID: id})
+	}
+	api.add(burst...)
+	if got := receiveIDs(t, stream, 150); !slices.Equal(got, ascending(9, 158)) {
+		t.Fatalf("got %v, want 9 to 158", got)
+	}
+}
+
+func TestStreamCancelWhileNotRead(t *testing.T) {
+	c := testClient(t, newFakeAPI(3))
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", RecheckInterval: time.Hour})
+
+	// Let the stream block on pushing its first torrent, which is never read.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	select {
+	case s, ok := <-stream:
+		if ok {
+			t.Fatalf("got %+v after cancelling, want the stream closed", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream blocked on a consumer that does not read after its context was cancelled")
+	}
+}
+
+func TestStreamMaxAge(t *testing.T) {
+	now := int(time.Now().Unix())
+	week := int((7 * 24 * time.Hour).Seconds())
+	for _, includeUnknown := range []bool{false, true} {
+		api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+		api.add(
+			Torrent{ID: 1, DateReleasedUnix: now - 4*week},
+			Torrent{ID: 2, DateReleasedUnix: now - 2*week},
+			Torrent{ID: 3},
+			Torrent{ID: 4, DateReleasedUnix: now - week/7},
+			Torrent{ID: 5, DateReleasedUnix: now},
+		)
+		c := testClient(t, api)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream := c.TorrentStream(ctx, StreamOptions{
+			ImdbID:              "tt1",
+			MaxAge:              time.Duration(week) * time.Second,
+			IncludeUnknownDates: includeUnknown,
+			RecheckInterval:     10 * time.Millisecond,
+		})
+		want := []int{4, 5}
+		if includeUnknown {
+			want = []int{3, 4, 5}
+		}
+		if got := receiveIDs(t, stream, len(want)); !slices.Equal(got, want) {
+			t.Fatalf("include unknown dates %t: got %v, want %v", includeUnknown, got, want)
+		}
+
+		// The skipped torrents are not delivered later on either.
+		api.add(Torrent{ID: 6, DateReleasedUnix: now})
+		if got := receiveIDs(t, stream, 1); !slices.Equal(got, []int{6}) {
+			t.Fatalf("include unknown dates %t: got %v after the backfill, want [6]", includeUnknown, got)
+		}
+	}
+}
+
+// fakeClock stands in for the clock of a stream. Sleeping returns at once and moves the clock forward.
+// Every sleep is recorded as an event, in order with the ones recorded by the test.
+type fakeClock struct {
+	mu     sync.Mutex
+	t      time.Time
+	events []string
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Unix(1_700_000_000, 0)}
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+	c.record("sleep " + d.String())
+	return ctx.Err()
+}
+
+func (c *fakeClock) record(event string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+}
+
+func (c *fakeClock) recorded() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.events)
+}
+
+func TestStreamResyncDelay(t *testing.T) {
+	api := newFakeAPI(350)
+	clock := newFakeClock()
+	timed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.record("request")
+		api.ServeHTTP(w, r)
+	})
+	c := testClient(t, timed)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{
+		ImdbID:          "tt1",
+		ResyncDelay:     50 * time.Millisecond,
+		RecheckInterval: time.Hour,
+		now:             clock.now,
+		sleep:           clock.sleep,
+	})
+	if got := receiveIDs(t, stream, 350); !slices.Equal(got, ascending(1, 350)) {
+		t.Fatalf("got %v, want 1 to 350", got)
+	}
+
+	// The probe for the torrents count and 4 pages, each after the delay.
+	want := []string{"request"}
+	for range 4 {
+		want = append(want, "sleep 50ms", "request")
+	}
+	if got := clock.recorded(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	const d, jitter = time.Minute, 10 * time.Second
+	if got := withJitter(d, 0); got != d {
+		t.Fatalf("got %s without jitter, want exactly %s", got, d)
+	}
+
+	// Every call draws a new jitter, so that rechecks keep spreading out.
+	seen := make(map[time.Duration]bool)
+	for range 100 {
+		got := withJitter(d, jitter)
+		if got < d-jitter || got > d+jitter {
+			t.Fatalf("got %s, want within %s of %s", got, jitter, d)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("got the same jitter on every call: %v", seen)
+	}
+
+	for range 100 {
+		if got := withJitter(time.Millisecond, time.Hour); got < 0 {
+			t.Fatalf("got the negative %s", got)
+		}
+	}
+}
+
+func TestStreamMaxEmitRate(t *testing.T) {
+	c := testClient(t, newFakeAPI(5))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := newFakeClock()
+	stream := c.TorrentStream(ctx, StreamOptions{
+		ImdbID:          "tt1",
+		MaxEmitRate:     20,
+		RecheckInterval: time.Hour,
+		now:             clock.now,
+		sleep:           clock.sleep,
+	})
+	for id := 1; id <= 5; id++ {
+		if s := receive(t, stream); s.Err != nil || s.ID != id {
+			t.Fatalf("got %d, %v, want %d", s.ID, s.Err, id)
+		}
+	}
+	// At 20 torrents per second, every torrent after the first is held back for 50ms.
+	want := slices.Repeat([]string{"sleep 50ms"}, 4)
+	if got := clock.recorded(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// A stream waiting for the rate to allow its next torrent stops once cancelled.
+	ctx, cancel = context.WithCancel(context.Background())
+	stream = c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", MaxEmitRate: rate.Every(time.Hour), RecheckInterval: time.Hour})
+	if s := receive(t, stream); s.Err != nil || s.ID != 1 {
+		t.Fatalf("got %d, %v, want 1", s.ID, s.Err)
+	}
+	cancel()
+	closed(t, stream)
+}
+
+func TestStreamResyncConcurrency(t *testing.T) {
+	const concurrency = 4
+	api := newFakeAPI(1000)
+	var inFlight, maxInFlight atomic.Int32
+	// Older pages, which are requested first, are the slowest, so the pages complete out of order.
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for m := maxInFlight.Load(); n > m && !maxInFlight.CompareAndSwap(m, n); m = maxInFlight.Load() {
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if r.URL.Query().Get("limit") != "1" {
+			time.Sleep(time.Duration(page) * 5 * time.Millisecond)
+		}
+		api.ServeHTTP(w, r)
+	})
+	c := testClient(t, slow)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", ResyncConcurrency: concurrency, RecheckInterval: time.Hour})
+	if got := receiveIDs(t, stream, 1000); !slices.Equal(got, ascending(1, 1000)) {
+		t.Fatalf("got torrents out of order under concurrency: %v", got)
+	}
+	if n := maxInFlight.Load(); n < 2 || n > concurrency {
+		t.Fatalf("fetched up to %d pages at once, want between 2 and %d", n, concurrency)
+	}
+}
+
+func TestStreamResyncConcurrencyCancel(t *testing.T) {
+	api := newFakeAPI(1000)
+	var hung, aborted atomic.Int32
+	// Only the oldest page is served, the others hang until their request is cancelled.
+	hanging := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("limit") != "1" && q.Get("page") != "10" {
+			hung.Add(1)
+			<-r.Context().Done()
+			aborted.Add(1)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	c := testClient(t, hanging)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", ResyncConcurrency: 4, RecheckInterval: time.Hour})
+	if got := receiveIDs(t, stream, 100); !slices.Equal(got, ascending(1, 100)) {
+		t.Fatalf("got %v, want 1 to 100", got)
+	}
+	// Pages 9 to 7 are fetched ahead, 6 too once the stream moves on from page 10.
+	deadline := time.Now().Add(5 * time.Second)
+	for hung.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	closed(t, stream)
+
+	for aborted.Load() < hung.Load() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n, m := aborted.Load(), hung.Load(); n != m || n < 3 || n > 4 {
+		t.Fatalf("aborted %d of %d in-flight fetches, want all of the 3 or 4 pages fetched ahead", n, m)
+	}
+}
+
+func TestMultiTorrentStream(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+	api.add(
+		Torrent{ID: 1, ImdbID: "1"},
+		Torrent{ID: 2, ImdbID: "2"},
+		Torrent{ID: 3, ImdbID: "1"},
+		Torrent{ID: 4, ImdbID: "2"},
+		Torrent{ID: 5, ImdbID: "1"},
+	)
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := c.MultiTorrentStream(ctx, []StreamOptions{
+		{ImdbID: "tt1", RecheckInterval: 10 * time.Millisecond},
+		{ImdbID: "tt12a34"},
+		{ImdbID: "tt2", RecheckInterval: 10 * time.Millisecond},
+	})
+	got := make(map[string][]int)
+	var errs []StreamTorrent
+	for range 6 {
+		s := receive(t, stream)
+		if s.Err != nil {
+			errs = append(errs, s)
+			continue
+		}
+		got[s.ImdbID] = append(got[s.ImdbID], s.ID)
+	}
+	if want := map[string][]int{"1": {1, 3, 5}, "2": {2, 4}}; !maps.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if len(errs) != 1 || errs[0].ImdbID != "12a34" || !errors.Is(errs[0].Err, ErrInvalidImdbID) {
+		t.Fatalf("got errors %+v, want ErrInvalidImdbID of 12a34", errs)
+	}
+
+	// The failed stream closed, but the others carry on.
+	api.add(Torrent{ID: 6, ImdbID: "2"})
+	if s := receive(t, stream); s.Err != nil || s.ID != 6 || s.ImdbID != "2" {
+		t.Fatalf("got %+v, want torrent 6 of tt2", s)
+	}
+
+	cancel()
+	closed(t, stream)
+}
+
+func TestStreamOnProgress(t *testing.T) {
+	api := newFakeAPI(250)
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received atomic.Int32
+	var mu sync.Mutex
+	var progress []int
+	stream := c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", RecheckInterval: 10 * time.Millisecond, OnProgress: func(lastTorrentID int) {
+		// The torrent with the ID was pushed already, but may not have been counted yet.
+		if n := int(received.Load()); lastTorrentID > n+1 {
+			t.Errorf("progressed to %d after only %d torrents were received", lastTorrentID, n)
+		}
+		mu.Lock()
+		progress = append(progress, lastTorrentID)
+		mu.Unlock()
+	}})
+	for id := 1; id <= 250; id++ {
+		if s := receive(t, stream); s.Err != nil || s.ID != id {
+			t.Fatalf("got %d, %v, want %d", s.ID, s.Err, id)
+		}
+		received.Store(int32(id))
+	}
+	api.add(torrentsWithIDs(251, 251)...)
+	if s := receive(t, stream); s.ID != 251 {
+		t.Fatalf("got %+v, want 251", s)
+	}
+	received.Store(251)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := len(progress) > 0 && progress[len(progress)-1] == 251
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	closed(t, stream)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) < 3 || progress[len(progress)-1] != 251 {
+		t.Fatalf("got progress %v, want it to advance through the re-sync up to 251", progress)
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i] <= progress[i-1] {
+			t.Fatalf("got progress %v, want every call to advance", progress)
+		}
+	}
+
+	// Resuming from the last progress only delivers the torrents published since.
+	api.add(torrentsWithIDs(252, 252)...)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	stream = c.TorrentStream(ctx, StreamOptions{ImdbID: "tt1", LastTorrentID: progress[len(progress)-1], RecheckInterval: 10 * time.Millisecond})
+	if s := receive(t, stream); s.Err != nil || s.ID != 252 {
+		t.Fatalf("got %d, %v after resuming, want 252", s.ID, s.Err)
+	}
+}
+
+func TestStreamCurrentBest(t *testing.T) {
+	api := &fakeAPI{maxLimit: MaxEZTVAPILimit}
+	api.add(
+		Torrent{ID: 1, Title: "Show.S01E01.480p.HDTV.x264-GRP", Season: "1", Episode: "1"},
+		Torrent{ID: 2, Title: "Show.S01E01.1080p.WEB.h264-GRP", Season: "1", Episode: "1"},
+		Torrent{ID: 3, Title: "Show.S01E01.720p.BluRay.x264-GRP", Season: "1", Episode: "1"},
+		Torrent{ID: 4, Title: "Show.S01E03.2160p.WEB.h265-GRP", Season: "1", Episode: "3"},
+		Torrent{ID: 5, Title: "Show.S01E02.1080p.WEB-DL.h264-OTHER", Season: "1", Episode: "2"},
+	)
+	c := testClient(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	have720p := Torrent{ID: 100, Title: "Show.S01E01.720p.HDTV.x264-GRP", Season: "1", Episode: "1"}
+	currentBest := map[SeasonEpisode]Torrent{
+		{1, 1}: have720p,
+		{1, 2}: {ID: 101, Title: "Show.S01E02.1080p.WEB-DL.h264-GRP", Season: "1", Episode: "2"},
+	}
+	stream := c.TorrentStream(ctx, StreamOptions{
+		ImdbID:          "tt1",
+		RecheckInterval: 10 * time.Millisecond,
+		CurrentBest:     currentBest,
+	})
+	// 1 is worse than the current best, 3 is worse than 2 emitted before it,
+	// 4 is of an episode that is not had and 5 is of the same quality.
+	if got := receiveIDs(t, stream, 1); !slices.Equal(got, []int{2}) {
+		t.Fatalf("got %v, want only the upgrade [2]", got)
+	}
+
+	// The skipped torrents are not delivered later on either.
+	api.add(
+		Torrent{ID: 6, Title: "Show.S01E01.1080p.HDTV.x264-GRP", Season: "1", Episode: "1"},
+		Torrent{ID: 7, Title: "Show.S01E02.1080p.BluRay.x264-GRP", Season: "1", Episode: "2"},
+	)
+	if got := receiveIDs(t, stream, 1); !slices.Equal(got, []int{7}) {
+		t.Fatalf("got %v after the new torrents, want only the upgrade [7]", got)
+	}
+
+	if currentBest[SeasonEpisode{1, 1}] != have720p {
+		t.Fatalf("the stream modified CurrentBest to %+v", currentBest[SeasonEpisode{1, 1}])
+	}
+}
@@ -1,11 +1,21 @@
 package eztv
 
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
 type Page struct {
 	ImdbID        string    `json:"imdb_id"`
 	TorrentsCount int       `json:"torrents_count"`
 	Limit         int       `json:"limit"`
 	Page          int       `json:"page"`
 	Torrents      []Torrent `json:"torrents"`
+
+	// BeyondLast is set when the requested page is past the last page of the query.
+	// Such pages have no torrents, which tells them apart from a show that has none.
+	BeyondLast bool `json:"-"`
 }
 
 type Torrent struct {
@@ -30,5 +40,46 @@ type Torrent struct {
 type StreamTorrent struct {
 	Torrent
 
-	Err error
+	// Snapshot is set when the torrent is part of a full page snapshot (see StreamOptions.FullSnapshotMode)
+	// rather than a newly added torrent.
+	Snapshot bool
+	Err      error
+}
+
+// SeasonEpisode identifies a single episode of a show.
+type SeasonEpisode struct {
+	Season  int
+	Episode int
+}
+
+// TorrentMagnet is a lightweight projection of Torrent holding only its ID and magnet link.
+type TorrentMagnet struct {
+	ID     int    `json:"id"`
+	Magnet string `json:"magnet_url"`
+}
+
+// ResponseMeta describes the API response a Page was decoded from.
+type ResponseMeta struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Header holds the response headers listed in ResponseMetaHeaders.
+	Header http.Header
+	// Elapsed is how long the request took, including decoding the body.
+	Elapsed time.Duration
+	// Query is the query sent to the API, after URLOptions were normalized.
+	Query url.Values
+}
+
+// ResponseMetaHeaders are the response headers copied into ResponseMeta.Header.
+var ResponseMetaHeaders = []string{
+	"Age",
+	"Cache-Control",
+	"CF-Cache-Status",
+	"Date",
+	"Retry-After",
+	"Server",
+	"X-Cache",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
 }
@@ -0,0 +1,241 @@
+package eztv
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SyncPlan computes how much work is needed to sync the show from lastTorrentID
+// up to its newest torrent. It returns the number of pages of MaxEZTVAPILimit torrents
+// to fetch and the number of torrents newer than lastTorrentID.
+//
+// If lastTorrentID is 0, the plan covers every torrent of the show.
+//
+// The plan is worked out without fetching the new torrents: it costs a single request when
+// lastTorrentID is among the newest MaxEZTVAPILimit torrents, or otherwise a binary search
+// of about log2 of the number of pages more.
+func (c *Client) SyncPlan(ctx context.Context, imdbID string, lastTorrentID int) (pagesToFetch int, newTorrents int, err error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return 0, 0, ErrMissingImdbID
+	}
+
+	newTorrents, err = c.countNewer(ctx, imdbID, lastTorrentID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pagesToFetch = pageCount(newTorrents, MaxEZTVAPILimit)
+	return pagesToFetch, newTorrents, nil
+}
+
+// countNewer returns the number of torrents of the show with an ID greater than lastTorrentID.
+// It looks for lastTorrentID on the first page, and otherwise binary searches the pages
+// for the first one that reaches it, relying on torrents being in descending ID order.
+func (c *Client) countNewer(ctx context.Context, imdbID string, lastTorrentID int) (int, error) {
+	// newerOn returns how many torrents of the page are newer than lastTorrentID
+	// and whether the page reaches lastTorrentID.
+	newerOn := func(page *Page) (int, bool) {
+		i := slices.IndexFunc(page.Torrents, func(t Torrent) bool { return t.ID <= lastTorrentID })
+		if i == -1 {
+			return len(page.Torrents), len(page.Torrents) == 0
+		}
+		return i, true
+	}
+	getPage := func(i, limit int) (*Page, error) {
+		return c.GetTorrents(ctx, URLOptions{
+			ImdbID: imdbID,
+			Page:   i,
+			Limit:  limit,
+		})
+	}
+
+	if lastTorrentID == 0 {
+		page, err := getPage(1, 1)
+		if err != nil {
+			return 0, err
+		}
+		return page.TorrentsCount, nil
+	}
+
+	first, err := getPage(1, MaxEZTVAPILimit)
+	if err != nil {
+		return 0, err
+	}
+	if n, ok := newerOn(first); ok {
+		return n, nil
+	}
+
+	// Every torrent of the first page is newer. Find the first page that reaches lastTorrentID,
+	// if any, among the rest. The pages hold as many torrents as the limit the API answered with,
+	// which may be less than the requested one.
+	limit := first.effectiveLimit(MaxEZTVAPILimit)
+	lo, hi := 2, first.lastPage(MaxEZTVAPILimit)
+	newer := first.TorrentsCount
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+		page, err := getPage(mid, MaxEZTVAPILimit)
+		if err != nil {
+			return 0, err
+		}
+		if n, ok := newerOn(page); ok {
+			newer = (mid-1)*limit + n
+			hi = mid - 1
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	return newer, nil
+}
+
+// newerTorrents returns the torrents of the show with an ID greater than lastTorrentID, newest first.
+// Pages are only fetched until the first torrent that is not newer is found.
+func (c *Client) newerTorrents(ctx context.Context, imdbID string, lastTorrentID int) ([]Torrent, error) {
+	var torrents []Torrent
+	err := c.forEachPage(ctx, imdbID, 0, func(page *Page) bool {
+		for _, torrent := range page.Torrents {
+			if torrent.ID <= lastTorrentID {
+				return false
+			}
+			torrents = append(torrents, torrent)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return torrents, nil
+}
+
+// Lag returns how many torrents of the show are newer than lastTorrentID.
+// It returns 0 when lastTorrentID is already the newest torrent.
+// Like SyncPlan, it does not fetch the new torrents to count them.
+func (c *Client) Lag(ctx context.Context, imdbID string, lastTorrentID int) (int, error) {
+	_, newTorrents, err := c.SyncPlan(ctx, imdbID, lastTorrentID)
+	return newTorrents, err
+}
+
+// StateStore persists the ID of the last synced torrent per show.
+type StateStore interface {
+	// Load returns the last synced torrent ID of the show, or 0 if it was never synced.
+	Load(ctx context.Context, imdbID string) (int, error)
+	// Save stores the last synced torrent ID of the show.
+	Save(ctx context.Context, imdbID string, lastTorrentID int) error
+}
+
+// SeenStore is a StateStore that also persists the IDs of the torrents a stream emitted most recently.
+// Streams given a SeenStore through StreamOptions.StateStore skip those torrents after a restart,
+// even if the last synced torrent ID was not saved for them.
+type SeenStore interface {
+	StateStore
+	// LoadSeen returns the IDs of the most recently emitted torrents of the show, oldest first.
+	LoadSeen(ctx context.Context, imdbID string) ([]int, error)
+	// SaveSeen stores the IDs of the most recently emitted torrents of the show, oldest first.
+	SaveSeen(ctx context.Context, imdbID string, ids []int) error
+}
+
+// MemoryStateStore is a StateStore and SeenStore that keeps the state in memory. It is safe for concurrent use.
+type MemoryStateStore struct {
+	mu   sync.Mutex
+	ids  map[string]int
+	seen map[string][]int
+}
+
+// NewMemoryStateStore returns an empty MemoryStateStore.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		ids:  make(map[string]int),
+		seen: make(map[string][]int),
+	}
+}
+
+// Load returns the last synced torrent ID of the show.
+func (s *MemoryStateStore) Load(_ context.Context, imdbID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ids[imdbID], nil
+}
+
+// Save stores the last synced torrent ID of the show.
+func (s *MemoryStateStore) Save(_ context.Context, imdbID string, lastTorrentID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[imdbID] = lastTorrentID
+	return nil
+}
+
+// LoadSeen returns the IDs of the most recently emitted torrents of the show.
+func (s *MemoryStateStore) LoadSeen(_ context.Context, imdbID string) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.seen[imdbID]), nil
+}
+
+// SaveSeen stores the IDs of the most recently emitted torrents of the show.
+func (s *MemoryStateStore) SaveSeen(_ context.Context, imdbID string, ids []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen[imdbID] = slices.Clone(ids)
+	return nil
+}
+
+// SyncOnce returns the torrents of the show added since the last sync recorded in store,
+// in ascending ID order, and then records the newest of them as the last synced torrent.
+// If the show was never synced, every torrent of the show is returned.
+//
+// If the new state cannot be saved, no torrents are returned, so that
+// the next run syncs the same torrents again.
+func (c *Client) SyncOnce(ctx context.Context, store StateStore, imdbID string) ([]Torrent, error) {
+	imdbID = strings.TrimPrefix(imdbID, "tt")
+	if imdbID == "" {
+		return nil, ErrMissingImdbID
+	}
+
+	lastTorrentID, err := store.Load(ctx, imdbID)
+	if err != nil {
+		return nil, fmt.Errorf("load state: %w", err)
+	}
+
+	torrents, err := c.newerTorrents(ctx, imdbID, lastTorrentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(torrents) == 0 {
+		return nil, nil
+	}
+
+	if err := store.Save(ctx, imdbID, torrents[0].ID); err != nil {
+		return nil, fmt.Errorf("save state: %w", err)
+	}
+
+	slices.Reverse(torrents)
+	return torrents, nil
+}
+
+// IsLatest reports whether the torrent is the newest torrent of its show.
+// It returns ErrMissingImdbID if the torrent has no ImdbID.
+func (c *Client) IsLatest(ctx context.Context, t Torrent) (bool, error) {
+	imdbID := strings.TrimPrefix(t.ImdbID, "tt")
+	if imdbID == "" {
+		return false, ErrMissingImdbID
+	}
+
+	page, err := c.GetTorrents(ctx, URLOptions{
+		ImdbID: imdbID,
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return false, err
+	}
+	if len(page.Torrents) == 0 {
+		return false, nil
+	}
+
+	return page.Torrents[0].ID == t.ID, nil
+}
@@ -0,0 +1,171 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSyncPlan(t *testing.T) {
+	tests := []struct {
+		name          string
+		oldest        int
+		lastTorrentID int
+		pages         int
+		newTorrents   int
+		maxRequests   int
+	}{
+		{name: "caught up", oldest: 1, lastTorrentID: 350, pages: 0, newTorrents: 0, maxRequests: 1},
+		{name: "on first page", oldest: 1, lastTorrentID: 340, pages: 1, newTorrents: 10, maxRequests: 1},
+		{name: "whole first page", oldest: 1, lastTorrentID: 250, pages: 1, newTorrents: 100, maxRequests: 3},
+		{name: "just past first page", oldest: 1, lastTorrentID: 249, pages: 2, newTorrents: 101, maxRequests: 3},
+		{name: "deep", oldest: 1, lastTorrentID: 100, pages: 3, newTorrents: 250, maxRequests: 3},
+		{name: "never synced", oldest: 1, lastTorrentID: 0, pages: 4, newTorrents: 350, maxRequests: 1},
+		{name: "older than every torrent", oldest: 51, lastTorrentID: 10, pages: 3, newTorrents: 300, maxRequests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeAPI{torrents: torrentsWithIDs(tt.oldest, 350), maxLimit: MaxEZTVAPILimit}
+			c := testClient(t, api)
+
+			pages, newTorrents, err := c.SyncPlan(context.Background(), "tt1", tt.lastTorrentID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pages != tt.pages || newTorrents != tt.newTorrents {
+				t.Errorf("got %d pages and %d torrents, want %d and %d", pages, newTorrents, tt.pages, tt.newTorrents)
+			}
+			if n := len(api.requests()); n > tt.maxRequests {
+				t.Errorf("made %d requests, want at most %d", n, tt.maxRequests)
+			}
+		})
+	}
+}
+
+func TestSyncPlanAnsweredLimit(t *testing.T) {
+	// The API answers with pages of 50 torrents, although SyncPlan asks for MaxEZTVAPILimit.
+	api := &fakeAPI{torrents: torrentsWithIDs(1, 350), maxLimit: 50}
+	pages, newTorrents, err := testClient(t, api).SyncPlan(context.Background(), "tt1", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 3 || newTorrents != 250 {
+		t.Errorf("got %d pages and %d torrents, want 3 and 250", pages, newTorrents)
+	}
+}
+
+func TestSyncPlanMissingImdbID(t *testing.T) {
+	if _, _, err := New().SyncPlan(context.Background(), "tt", 1); !errors.Is(err, ErrMissingImdbID) {
+		t.Fatalf("got %v, want ErrMissingImdbID", err)
+	}
+}
+
+func TestSyncPlanDoesNotFetchNewTorrents(t *testing.T) {
+	api := newFakeAPI(5000)
+	c := testClient(t, api)
+
+	pages, newTorrents, err := c.SyncPlan(context.Background(), "tt1", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 49 || newTorrents != 4900 {
+		t.Errorf("got %d pages and %d torrents, want 49 and 4900", pages, newTorrents)
+	}
+	// The first page and a binary search of the other 49.
+	if n := len(api.requests()); n > 7 {
+		t.Errorf("made %d requests, want at most 7", n)
+	}
+}
+
+func TestLag(t *testing.T) {
+	api := newFakeAPI(1000)
+	c := testClient(t, api)
+
+	for lastTorrentID, want := range map[int]int{1000: 0, 999: 1, 950: 50, 500: 500, 1: 999} {
+		lag, err := c.Lag(context.Background(), "1", lastTorrentID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lag != want {
+			t.Errorf("lag behind %d = %d, want %d", lastTorrentID, lag, want)
+		}
+	}
+}
+
+func TestSyncOnce(t *testing.T) {
+	api := newFakeAPI(150)
+	c := testClient(t, api)
+	store := &recordingStore{MemoryStateStore: NewMemoryStateStore()}
+
+	torrents, err := c.SyncOnce(context.Background(), store, "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(torrents); !slices.Equal(got, ascending(1, 150)) {
+		t.Fatalf("first sync got %d torrents, want 1 to 150", len(got))
+	}
+
+	torrents, err = c.SyncOnce(context.Background(), store, "tt1")
+	if err != nil || len(torrents) != 0 {
+		t.Fatalf("got %v, %v without new torrents, want none", ids(torrents), err)
+	}
+
+	api.add(Torrent{ID: 151}, Torrent{ID: 152}, Torrent{ID: 153})
+	torrents, err = c.SyncOnce(context.Background(), store, "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(torrents); !slices.Equal(got, []int{151, 152, 153}) {
+		t.Fatalf("got %v, want [151 152 153]", got)
+	}
+	if saved := store.savedIDs(); !slices.Equal(saved, []int{150, 153}) {
+		t.Fatalf("saved %v, want [150 153]", saved)
+	}
+
+	// A failure to save returns no torrents, so the next run syncs them again.
+	api.add(Torrent{ID: 154})
+	store.failID = 154
+	if torrents, err := c.SyncOnce(context.Background(), store, "tt1"); err == nil || torrents != nil {
+		t.Fatalf("got %v, %v, want the error saving the state", ids(torrents), err)
+	}
+	store.failID = 0
+	torrents, err = c.SyncOnce(context.Background(), store, "tt1")
+	if err != nil || !slices.Equal(ids(torrents), []int{154}) {
+		t.Fatalf("got %v, %v, want [154] synced again", ids(torrents), err)
+	}
+
+	// Pages are walked with the limit the API answered with, not the requested one.
+	capped := &fakeAPI{torrents: torrentsWithIDs(1, 150), maxLimit: 50}
+	torrents, err = testClient(t, capped).SyncOnce(context.Background(), NewMemoryStateStore(), "tt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(torrents); !slices.Equal(got, ascending(1, 150)) {
+		t.Fatalf("got %d torrents from pages of 50, want 1 to 150", len(got))
+	}
+}
+
+func TestIsLatest(t *testing.T) {
+	api := newFakeAPI(3)
+	c := testClient(t, api)
+
+	for id, want := range map[int]bool{3: true, 2: false} {
+		latest, err := c.IsLatest(context.Background(), Torrent{ID: id, ImdbID: "tt1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if latest != want {
+			t.Errorf("torrent %d: got latest %t, want %t", id, latest, want)
+		}
+	}
+	for _, q := range api.requests() {
+		if q.Get("page") != "1" || q.Get("limit") != "1" || q.Get("imdb_id") != "1" {
+			t.Fatalf("requested %v, want page 1 with limit 1 of the show", q)
+		}
+	}
+
+	if _, err := c.IsLatest(context.Background(), Torrent{ID: 3}); !errors.Is(err, ErrMissingImdbID) {
+		t.Fatalf("got %v, want ErrMissingImdbID", err)
+	}
+}
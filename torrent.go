@@ -0,0 +1,80 @@
+package eztv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSize = errors.New("invalid size")
+
+// Size returns the size of the torrent in bytes, parsed from SizeBytes.
+// It returns an error wrapping ErrInvalidSize if SizeBytes is empty or malformed.
+func (t Torrent) Size() (int64, error) {
+	s := strings.TrimSpace(t.SizeBytes)
+	if s == "" {
+		return 0, fmt.Errorf("%w: empty size_bytes", ErrInvalidSize)
+	}
+
+	size, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %w", ErrInvalidSize, t.SizeBytes, err)
+	}
+	if size < 0 {
+		return 0, fmt.Errorf("%w %q: negative size", ErrInvalidSize, t.SizeBytes)
+	}
+
+	return size, nil
+}
+
+// SizeHuman returns the size of the torrent formatted with binary units, e.g. "1.4 GiB".
+// It returns an empty string if the size cannot be parsed.
+func (t Torrent) SizeHuman() string {
+	size, err := t.Size()
+	if err != nil {
+		return ""
+	}
+
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// ReleasedAt returns the release time of the torrent in UTC, parsed from DateReleasedUnix.
+// If the release date is unknown, it returns the zero time.Time.
+func (t Torrent) ReleasedAt() time.Time {
+	if t.DateReleasedUnix == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(t.DateReleasedUnix), 0).UTC()
+}
+
+// SeasonNumber returns the season of the torrent parsed from Season.
+// ok is false if Season is empty, as it is for some season packs, or not a valid number.
+func (t Torrent) SeasonNumber() (season int, ok bool) {
+	return parseNumber(t.Season)
+}
+
+// EpisodeNumber returns the episode of the torrent parsed from Episode.
+// ok is false if Episode is empty, as it is for season packs, or not a valid number.
+func (t Torrent) EpisodeNumber() (episode int, ok bool) {
+	return parseNumber(t.Episode)
+}
+
+// parseNumber parses a numeric string field of the API, such as season or episode.
+func parseNumber(s string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,71 @@
+package eztv
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		sizeBytes string
+		want      int64
+		wantErr   bool
+		human     string
+	}{
+		{"1503238553", 1503238553, false, "1.4 GiB"},
+		{" 1503238553\n", 1503238553, false, "1.4 GiB"},
+		{"0", 0, false, "0 B"},
+		{"1023", 1023, false, "1023 B"},
+		{"1536", 1536, false, "1.5 KiB"},
+		{"", 0, true, ""},
+		{"1.4 GB", 0, true, ""},
+		{"-1", 0, true, ""},
+	}
+	for _, tt := range tests {
+		torrent := Torrent{SizeBytes: tt.sizeBytes}
+		got, err := torrent.Size()
+		if got != tt.want || (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidSize)) {
+			t.Errorf("%q: got %d, %v, want %d and an error %t", tt.sizeBytes, got, err, tt.want, tt.wantErr)
+		}
+		if human := torrent.SizeHuman(); human != tt.human {
+			t.Errorf("%q: got %q, want %q", tt.sizeBytes, human, tt.human)
+		}
+	}
+}
+
+func TestReleasedAt(t *testing.T) {
+	got := Torrent{DateReleasedUnix: 1_700_000_000}.ReleasedAt()
+	if want := time.Date(2023, time.November, 14, 22, 13, 20, 0, time.UTC); !got.Equal(want) || got.Location() != time.UTC {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	if got := (Torrent{}).ReleasedAt(); !got.IsZero() {
+		t.Fatalf("got %s for an unknown release date, want the zero time", got)
+	}
+}
+
+func TestSeasonAndEpisodeNumber(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   int
+		wantOK bool
+	}{
+		{"0", 0, true},
+		{"", 0, false},
+		{"07", 7, true},
+		{"12", 12, true},
+		{" 3 ", 3, true},
+		{"-1", 0, false},
+		{"S01", 0, false},
+		{"1-2", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := (Torrent{Season: tt.value}).SeasonNumber(); got != tt.want || ok != tt.wantOK {
+			t.Errorf("season %q: got %d, %t, want %d, %t", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+		if got, ok := (Torrent{Episode: tt.value}).EpisodeNumber(); got != tt.want || ok != tt.wantOK {
+			t.Errorf("episode %q: got %d, %t, want %d, %t", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package eztv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MaxTorrentFileSize is the size above which DownloadTorrentFile gives up on a .torrent file,
+// far more than any real one takes, to not read a misconfigured URL into memory.
+const MaxTorrentFileSize = 10 << 20
+
+var (
+	ErrMissingTorrentURL   = errors.New("missing torrent url")
+	ErrTorrentFileTooLarge = errors.New("torrent file too large")
+)
+
+// DownloadTorrentFile downloads the .torrent file of the torrent from its TorrentURL and returns its contents.
+//
+// The file is requested with the client's http.Client, user agent, request timeout and retries.
+// It is no API request, so it is neither rate limited nor signed, and it is not passed to
+// the request and response hooks or observed by the Metrics.
+// A non-successful status code is returned as an *APIError.
+// If the torrent has no TorrentURL, it returns ErrMissingTorrentURL.
+//
+// The body is read under ctx, so cancelling it aborts a slow download midway. A file larger than
+// MaxTorrentFileSize is not read any further and returns ErrTorrentFileTooLarge.
+func (c *Client) DownloadTorrentFile(ctx context.Context, t Torrent) ([]byte, error) {
+	if t.TorrentURL == "" {
+		return nil, ErrMissingTorrentURL
+	}
+
+	req, err := c.newRequest(ctx, t.TorrentURL)
+	if err != nil {
+		return nil, err
+	}
+
+	b, _, err := do(c, req, requestOptions{download: true}, func(body io.Reader) (*[]byte, error) {
+		b, err := io.ReadAll(io.LimitReader(body, MaxTorrentFileSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > MaxTorrentFileSize {
+			return nil, fmt.Errorf("%w: over %d bytes", ErrTorrentFileTooLarge, MaxTorrentFileSize)
+		}
+		return &b, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return *b, nil
+}
@@ -0,0 +1,119 @@
+package eztv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestDownloadTorrentFile(t *testing.T) {
+	file := []byte("d8:announce30:udp://tracker.example:1337e")
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/missing.torrent":
+			http.NotFound(w, r)
+		case requests.Add(1) == 1:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case r.UserAgent() != "my-app/1.0":
+			http.Error(w, "forbidden", http.StatusForbidden)
+		default:
+			w.Write(file)
+		}
+	}))
+	defer srv.Close()
+	c := New(WithUserAgent("my-app/1.0"), WithRetry(2, time.Millisecond))
+
+	// The file is requested with the user agent and retries of the client.
+	got, err := c.DownloadTorrentFile(context.Background(), Torrent{TorrentURL: srv.URL + "/show.torrent"})
+	if err != nil || !bytes.Equal(got, file) {
+		t.Fatalf("got %q, %v, want %q", got, err, file)
+	}
+
+	_, err = c.DownloadTorrentFile(context.Background(), Torrent{TorrentURL: srv.URL + "/missing.torrent"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("got %v, want an *APIError with status 404", err)
+	}
+
+	if _, err := c.DownloadTorrentFile(context.Background(), Torrent{}); !errors.Is(err, ErrMissingTorrentURL) {
+		t.Fatalf("got %v, want ErrMissingTorrentURL", err)
+	}
+}
+
+func TestDownloadTorrentFileCancelDuringBody(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("d8:announce"))
+		w.(http.Flusher).Flush()
+		select { // Stall the rest of the body.
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := New().DownloadTorrentFile(ctx, Torrent{TorrentURL: srv.URL})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("returned %s after the cancellation", elapsed)
+	}
+}
+
+func TestDownloadTorrentFileTooLarge(t *testing.T) {
+	c := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("x"), MaxTorrentFileSize+1))
+	}))
+	_, err := c.DownloadTorrentFile(context.Background(), Torrent{TorrentURL: c.mirrors[0]})
+	if !errors.Is(err, ErrTorrentFileTooLarge) {
+		t.Fatalf("got %v, want ErrTorrentFileTooLarge", err)
+	}
+}
+
+func TestDownloadTorrentFileIsNoAPIRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("d8:announce30:udp://tracker.example:1337e"))
+	}))
+	defer srv.Close()
+
+	var hooked atomic.Int32
+	metrics := &clientsMetrics{}
+	c := New(
+		WithRateLimit(rate.Every(time.Hour), 1),
+		WithMetrics(metrics),
+		WithRequestSigner(func(*http.Request) error { return errors.New("api credentials only") }),
+		WithRequestHook(func(*http.Request) { hooked.Add(1) }),
+		WithResponseHook(func(*http.Response, error, time.Duration) { hooked.Add(1) }),
+	)
+
+	// Downloads do not use up the rate limit of the API, so the second one is not held back.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for range 2 {
+		if _, err := c.DownloadTorrentFile(ctx, Torrent{TorrentURL: srv.URL + "/show.torrent"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := hooked.Load(); n != 0 {
+		t.Fatalf("the downloads were passed to the hooks %d times, want none", n)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.clients) != 0 {
+		t.Fatalf("observed %d downloads as API requests, want none", len(metrics.clients))
+	}
+}
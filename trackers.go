@@ -0,0 +1,141 @@
+package eztv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTrackerTimeout is the default time ValidateTrackers waits for each tracker to respond.
+const DefaultTrackerTimeout = 5 * time.Second
+
+var ErrMissingMagnet = errors.New("missing magnet url")
+
+// udpTrackerProtocolID is the magic constant of the UDP tracker protocol (BEP 15).
+const udpTrackerProtocolID = 0x41727101980
+
+// ValidateTrackers returns the trackers of the torrent's magnet link that respond within
+// the timeout set by WithTrackerTimeout, in the order they appear in the magnet link.
+//
+// UDP trackers must answer a connect request of the UDP tracker protocol, HTTP(S) trackers
+// any HTTP request. Trackers with other schemes are treated as unreachable.
+func (c *Client) ValidateTrackers(ctx context.Context, t Torrent) ([]string, error) {
+	if t.MagnetURL == "" {
+		return nil, ErrMissingMagnet
+	}
+	trackers := magnetTrackers(t.MagnetURL)
+
+	reachable := make([]bool, len(trackers))
+	var wg sync.WaitGroup
+	for i, tracker := range trackers {
+		wg.Add(1)
+		go func(i int, tracker string) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, c.trackerTimeout)
+			defer cancel()
+			reachable[i] = c.trackerReachable(ctx, tracker)
+		}(i, tracker)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var alive []string
+	for i, tracker := range trackers {
+		if reachable[i] {
+			alive = append(alive, tracker)
+		}
+	}
+	return alive, nil
+}
+
+func (c *Client) trackerReachable(ctx context.Context, tracker string) bool {
+	u, err := url.Parse(tracker)
+	if err != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case "udp":
+		return udpTrackerReachable(ctx, u.Host)
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, tracker, nil)
+		if err != nil {
+			return false
+		}
+		resp, err := c.trackerClient().Do(req)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return true
+	}
+
+	return false
+}
+
+// trackerClient returns the http.Client HTTP trackers are probed with. Trackers are no EZTV API,
+// so they are neither recorded nor replayed, and only the timeout of the configured client is kept.
+func (c *Client) trackerClient() *http.Client {
+	client := &http.Client{}
+	if c.client != nil {
+		client.Timeout = c.client.Timeout
+	}
+	return client
+}
+
+// udpTrackerReachable sends a connect request to the UDP tracker and waits for a matching response.
+func udpTrackerReachable(ctx context.Context, host string) bool {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", host)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := make([]byte, 16)
+	binary.BigEndian.PutUint64(req[0:8], udpTrackerProtocolID)
+	binary.BigEndian.PutUint32(req[8:12], 0) // Action: connect.
+	if _, err := rand.Read(req[12:16]); err != nil {
+		return false
+	}
+	if _, err := conn.Write(req); err != nil {
+		return false
+	}
+
+	resp := make([]byte, 16)
+	n, err := conn.Read(resp)
+	if err != nil || n < 16 {
+		return false
+	}
+
+	return binary.BigEndian.Uint32(resp[0:4]) == 0 && string(resp[4:8]) == string(req[12:16])
+}
+
+// magnetTrackers returns the decoded tr parameters of the magnet link.
+func magnetTrackers(magnet string) []string {
+	_, query, ok := strings.Cut(magnet, "?")
+	if !ok {
+		return nil
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil
+	}
+	return values["tr"]
+}
@@ -0,0 +1,108 @@
+package eztv
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// udpTracker starts a UDP tracker on localhost. If respond is set, it answers connect requests
+// as the UDP tracker protocol does, otherwise it never responds. It returns the tracker URL.
+func udpTracker(t *testing.T, respond bool) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if !respond || n < 16 || binary.BigEndian.Uint64(buf[0:8]) != udpTrackerProtocolID {
+				continue
+			}
+
+			resp := make([]byte, 16)
+			binary.BigEndian.PutUint32(resp[0:4], 0) // Action: connect.
+			copy(resp[4:8], buf[12:16])              // Transaction ID.
+			binary.BigEndian.PutUint64(resp[8:16], 42)
+			conn.WriteTo(resp, addr)
+		}
+	}()
+
+	return "udp://" + conn.LocalAddr().String() + "/announce"
+}
+
+func TestValidateTrackers(t *testing.T) {
+	httpTracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(httpTracker.Close)
+	closedTracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closedTracker.Close()
+
+	reachableUDP, silentUDP := udpTracker(t, true), udpTracker(t, false)
+	trackers := []string{
+		silentUDP,
+		httpTracker.URL + "/announce",
+		reachableUDP,
+		closedTracker.URL + "/announce",
+		"wss://tracker.example/announce",
+	}
+	magnet := "magnet:?xt=urn:btih:abcdef0123456789abcdef0123456789abcdef01"
+	for _, tracker := range trackers {
+		magnet += "&tr=" + url.QueryEscape(tracker)
+	}
+
+	const timeout = 200 * time.Millisecond
+	c := New(WithTrackerTimeout(timeout))
+	start := time.Now()
+	alive, err := c.ValidateTrackers(context.Background(), Torrent{MagnetURL: magnet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{httpTracker.URL + "/announce", reachableUDP}; !slices.Equal(alive, want) {
+		t.Fatalf("got reachable trackers %v, want %v", alive, want)
+	}
+	// Trackers are checked concurrently, so the silent one bounds the total time.
+	if elapsed := time.Since(start); elapsed < timeout || elapsed > 5*timeout {
+		t.Fatalf("validating took %s, want about the %s tracker timeout", elapsed, timeout)
+	}
+
+	if _, err := c.ValidateTrackers(context.Background(), Torrent{}); !errors.Is(err, ErrMissingMagnet) {
+		t.Fatalf("got %v, want ErrMissingMagnet", err)
+	}
+}
+
+func TestValidateTrackersBypassesRecordAndReplay(t *testing.T) {
+	httpTracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(httpTracker.Close)
+	tracker := httpTracker.URL + "/announce"
+	torrent := Torrent{MagnetURL: "magnet:?xt=urn:btih:abcdef0123456789abcdef0123456789abcdef01&tr=" + url.QueryEscape(tracker)}
+
+	// Nothing was recorded, so a replayed probe would find the tracker unreachable.
+	c := New(WithReplayer(t.TempDir()))
+	if alive, err := c.ValidateTrackers(context.Background(), torrent); err != nil || !slices.Equal(alive, []string{tracker}) {
+		t.Fatalf("replaying client: got %v, %v, want %v", alive, err, []string{tracker})
+	}
+
+	dir := t.TempDir()
+	c = New(WithRecorder(dir))
+	if alive, err := c.ValidateTrackers(context.Background(), torrent); err != nil || !slices.Equal(alive, []string{tracker}) {
+		t.Fatalf("recording client: got %v, %v, want %v", alive, err, []string{tracker})
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Fatalf("recorded %v, %v, want no recordings of tracker probes", entries, err)
+	}
+}